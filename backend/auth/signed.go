@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"strings"
+)
+
+// SignValue returns value followed by a signature scoped to purpose, so a
+// value stored by clients, such as a cookie, cannot be forged or reused for
+// another purpose. It is signed with the magic link secret.
+func SignValue(purpose, value string) string {
+	if secret == nil {
+		InitMagicLink("")
+	}
+	return value + "." + sign(purpose+":"+value)
+}
+
+// VerifyValue returns the value of signed when its signature matches purpose
+func VerifyValue(purpose, signed string) (string, bool) {
+	if secret == nil {
+		InitMagicLink("")
+	}
+
+	i := strings.LastIndex(signed, ".")
+	if i < 0 {
+		return "", false
+	}
+
+	value := signed[:i]
+	if !hmac.Equal([]byte(sign(purpose+":"+value)), []byte(signed[i+1:])) {
+		return "", false
+	}
+	return value, true
+}
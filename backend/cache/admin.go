@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Namespaces are the key prefixes owned by the cache. Admin operations are
+// limited to them so that other data in Redis, such as rate limit counters,
+// is never listed or evicted.
+var Namespaces = []string{
+	JobsNamespace,
+	JobNamespace,
+	LocationsNamespace,
+	ApplicationsNamespace,
+	ApplicationNamespace,
+}
+
+// ErrUnknownNamespace is returned for a namespace not in Namespaces
+var ErrUnknownNamespace = errors.New("unknown cache namespace")
+
+// Tiers reported in KeyInfo
+const (
+	TierLocal = "local"
+	TierRedis = "redis"
+)
+
+// KeyInfo describes a cached key
+type KeyInfo struct {
+	Key  string `json:"key" example:"job:42"`
+	Tier string `json:"tier" example:"redis"`
+
+	// TTLSeconds is the time left before the key expires, -1 without expiration
+	TTLSeconds int64 `json:"ttl_seconds" example:"540"`
+}
+
+// Inspector is implemented by caches that can list and evict keys by prefix
+type Inspector interface {
+	// Keys returns up to limit keys starting with prefix
+	Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error)
+
+	// DeletePrefix removes every key starting with prefix and returns how many were removed
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// NamespaceStats counts the lookups of a namespace since startup
+type NamespaceStats struct {
+	Hits     uint64  `json:"hits" example:"950"`
+	Misses   uint64  `json:"misses" example:"50"`
+	Errors   uint64  `json:"errors" example:"0"`
+	HitRatio float64 `json:"hit_ratio" example:"0.95"`
+}
+
+// Stats describes the cache store and its lookups
+type Stats struct {
+	Mode         string                    `json:"mode" example:"tiered"`
+	LocalEntries int                       `json:"local_entries" example:"120"`
+	Namespaces   map[string]NamespaceStats `json:"namespaces"`
+}
+
+// lookupCounts holds the lookup counters of a namespace
+type lookupCounts struct {
+	hits, misses, errors atomic.Uint64
+}
+
+// lookups maps a key prefix to its *lookupCounts
+var lookups sync.Map
+
+// countsFor returns the lookup counters of the prefix of key
+func countsFor(key string) *lookupCounts {
+	counts, _ := lookups.LoadOrStore(keyPrefix(key), &lookupCounts{})
+	return counts.(*lookupCounts)
+}
+
+// recordHit counts a lookup of key that found a value
+func recordHit(key string) {
+	cacheHits.WithLabelValues(keyPrefix(key)).Inc()
+	countsFor(key).hits.Add(1)
+}
+
+// recordMiss counts a lookup of key that found no value
+func recordMiss(key string) {
+	cacheMisses.WithLabelValues(keyPrefix(key)).Inc()
+	countsFor(key).misses.Add(1)
+}
+
+// recordError counts a lookup of key that failed
+func recordError(key string) {
+	cacheErrors.WithLabelValues(keyPrefix(key)).Inc()
+	countsFor(key).errors.Add(1)
+}
+
+// GetCacheStats returns the cache mode, the number of local entries and the
+// hit ratio of every namespace
+func GetCacheStats() Stats {
+	stats := Stats{
+		Mode:       mode,
+		Namespaces: make(map[string]NamespaceStats, len(Namespaces)),
+	}
+
+	switch s := store.(type) {
+	case *LocalCache:
+		stats.LocalEntries = s.Len()
+	case *TieredCache:
+		stats.LocalEntries = s.local.Len()
+	case *FallbackCache:
+		stats.LocalEntries = s.local.Len()
+	}
+
+	for _, namespace := range Namespaces {
+		var ns NamespaceStats
+		if value, ok := lookups.Load(namespace); ok {
+			counts := value.(*lookupCounts)
+			ns.Hits = counts.hits.Load()
+			ns.Misses = counts.misses.Load()
+			ns.Errors = counts.errors.Load()
+		}
+		if total := ns.Hits + ns.Misses + ns.Errors; total > 0 {
+			ns.HitRatio = float64(ns.Hits) / float64(total)
+		}
+		stats.Namespaces[namespace] = ns
+	}
+
+	return stats
+}
+
+// NamespaceKeys returns up to limit keys of namespace with their TTL
+func NamespaceKeys(ctx context.Context, namespace string, limit int) ([]KeyInfo, error) {
+	if !slices.Contains(Namespaces, namespace) {
+		return nil, ErrUnknownNamespace
+	}
+
+	inspector, ok := store.(Inspector)
+	if !ok {
+		return nil, nil
+	}
+	return inspector.Keys(ctx, namespace+":", limit)
+}
+
+// EvictNamespace removes every key of namespace, on every instance, and
+// returns how many keys were removed from this instance's store
+func EvictNamespace(ctx context.Context, namespace string) (int, error) {
+	if !slices.Contains(Namespaces, namespace) {
+		return 0, ErrUnknownNamespace
+	}
+
+	inspector, ok := store.(Inspector)
+	if !ok {
+		return 0, nil
+	}
+
+	prefix := namespace + ":"
+	removed, err := inspector.DeletePrefix(ctx, prefix)
+	publishPrefixInvalidation(ctx, prefix)
+	return removed, err
+}
+
+// ttlSeconds converts the remaining lifetime of a key to whole seconds, -1
+// when the key does not expire
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl < 0 {
+		return -1
+	}
+	return int64(ttl.Round(time.Second) / time.Second)
+}
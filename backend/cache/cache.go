@@ -0,0 +1,302 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"job-portal-backend/config"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Cache modes
+const (
+	ModeRedis  = "redis"
+	ModeLocal  = "local"
+	ModeTiered = "tiered"
+)
+
+// ErrMiss is returned by Get when the key is not cached
+var ErrMiss = errors.New("cache miss")
+
+// Cache stores encoded values by key until they expire
+type Cache interface {
+	// Get returns the value of key, or ErrMiss when it is absent or expired
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key; a zero expiration keeps it until evicted
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+var (
+	// store is the cache used by the package functions. It is local until
+	// InitCache selects the configured mode, so callers never see a nil cache.
+	store Cache = NewLocalCache(config.Default().Cache.LocalMaxEntries)
+
+	// mode is the mode of store
+	mode = ModeLocal
+)
+
+// InitCache selects the cache store of cfg. It must run after InitRedis;
+// without Redis every mode uses the local store.
+func InitCache(cfg config.CacheConfig) {
+	staleTTL = cfg.StaleTTL
+	earlyRefresh = cfg.EarlyRefresh
+	operationTimeouts = cfg.OperationTimeouts
+
+	local := NewLocalCache(cfg.LocalMaxEntries)
+	mode = strings.ToLower(cfg.Mode)
+
+	if mode != ModeLocal && redisClient == nil {
+		slog.Warn("Redis not available, cache using local store", "mode", mode)
+		store = local
+		mode = ModeLocal
+		return
+	}
+
+	switch mode {
+	case ModeLocal:
+		store = local
+	case ModeTiered:
+		tiered = NewTieredCache(local, NewRedisCache(redisClient), cfg.LocalTTL)
+		store = tiered
+		startInvalidationSubscriber()
+	default:
+		// Falls back to the local store while Redis is down at runtime
+		store = NewFallbackCache(local, NewRedisCache(redisClient), cfg.LocalTTL)
+	}
+
+	slog.Info("Cache initialized", "mode", mode)
+}
+
+// Store returns the cache used by the package functions
+func Store() Cache {
+	return store
+}
+
+var (
+	cacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "Cache lookups that found a value by key prefix.",
+	}, []string{"prefix"})
+
+	cacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_misses_total",
+		Help: "Cache lookups that found no value by key prefix.",
+	}, []string{"prefix"})
+
+	cacheErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_errors_total",
+		Help: "Cache lookups that failed by key prefix.",
+	}, []string{"prefix"})
+)
+
+// keyPrefix returns the part of a cache key before the first colon, which
+// names the kind of data without the ID
+func keyPrefix(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// Set sets a key-value pair in cache
+func Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, opSet)
+	defer cancel()
+	return store.Set(ctx, key, jsonValue, expiration)
+}
+
+// Get retrieves a value from cache
+func Get(ctx context.Context, key string, dest interface{}) error {
+	getCtx, cancel := withOperationTimeout(ctx, opGet)
+	defer cancel()
+
+	val, err := store.Get(getCtx, key)
+	switch {
+	case errors.Is(err, ErrMiss):
+		recordMiss(key)
+		return err
+	case err != nil:
+		recordError(key)
+		return err
+	}
+	recordHit(key)
+
+	return json.Unmarshal(val, dest)
+}
+
+// Delete removes keys from cache, including the local tier of every instance
+func Delete(ctx context.Context, keys ...string) error {
+	deleteCtx, cancel := withOperationTimeout(ctx, opInvalidate)
+	defer cancel()
+
+	err := store.Delete(deleteCtx, keys...)
+	publishInvalidation(ctx, keys...)
+	return err
+}
+
+// Listing keys are versioned per namespace. Invalidating a namespace moves it
+// to a new version, so every filter and page combination cached under the old
+// version is dropped at once and simply expires.
+const (
+	namespaceVersionKey = "%s:version"
+	listingKey          = "%s:list:%s:%s"
+)
+
+// ListingKey returns the cache key of a listing in namespace. The key is
+// derived from the canonical encoding of params, sorted by name, so the same
+// filters always give the same key whatever order they were sent in.
+func ListingKey(ctx context.Context, namespace string, params url.Values) (string, error) {
+	ctx, cancel := withOperationTimeout(ctx, opGet)
+	defer cancel()
+
+	version, err := namespaceVersion(ctx, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(params.Encode()))
+	return fmt.Sprintf(listingKey, namespace, version, hex.EncodeToString(sum[:16])), nil
+}
+
+// InvalidateNamespace drops every listing cached in namespace, on every instance
+func InvalidateNamespace(ctx context.Context, namespace string) error {
+	versionCtx, cancel := withOperationTimeout(ctx, opInvalidate)
+	defer cancel()
+
+	_, err := newNamespaceVersion(versionCtx, namespace)
+	publishInvalidation(ctx, fmt.Sprintf(namespaceVersionKey, namespace))
+	return err
+}
+
+// namespaceVersion returns the current version of namespace, starting a new
+// one when none is cached. Version lookups bypass the hit and miss metrics.
+func namespaceVersion(ctx context.Context, namespace string) (string, error) {
+	version, err := store.Get(ctx, fmt.Sprintf(namespaceVersionKey, namespace))
+	if errors.Is(err, ErrMiss) {
+		return newNamespaceVersion(ctx, namespace)
+	}
+	return string(version), err
+}
+
+// newNamespaceVersion stores a new unique version for namespace
+func newNamespaceVersion(ctx context.Context, namespace string) (string, error) {
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := store.Set(ctx, fmt.Sprintf(namespaceVersionKey, namespace), []byte(version), 0); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// Cache namespaces, the prefix before the first colon of their keys
+const (
+	JobsNamespace         = "jobs"
+	JobNamespace          = "job"
+	LocationsNamespace    = "locations"
+	ApplicationsNamespace = "applications"
+	ApplicationNamespace  = "application"
+)
+
+// Cache keys for different data types
+const (
+	// Job cache keys
+	JobCacheKey       = JobNamespace + ":%d"
+	LocationsCacheKey = LocationsNamespace + ":all"
+
+	// Application cache keys
+	ApplicationsCacheKey = ApplicationsNamespace + ":all"
+	ApplicationCacheKey  = ApplicationNamespace + ":%d"
+
+	// Cache expiration times
+	JobsCacheExpiration         = 5 * time.Minute
+	JobCacheExpiration          = 10 * time.Minute
+	LocationsCacheExpiration    = 30 * time.Minute
+	ApplicationsCacheExpiration = 2 * time.Minute
+	ApplicationCacheExpiration  = 5 * time.Minute
+)
+
+// JobListingKey returns the cache key of the job listing selected by params
+func JobListingKey(ctx context.Context, params url.Values) (string, error) {
+	return ListingKey(ctx, JobsNamespace, params)
+}
+
+// FetchJobs reads the job listing under its listing key, loading it on a miss
+func FetchJobs(ctx context.Context, key string, dest interface{}, load LoadFunc) error {
+	return Fetch(ctx, key, JobsCacheExpiration, dest, load)
+}
+
+// FetchJob reads a job, loading it on a miss
+func FetchJob(ctx context.Context, jobID int, dest interface{}, load LoadFunc) error {
+	key := fmt.Sprintf(JobCacheKey, jobID)
+	return Fetch(ctx, key, JobCacheExpiration, dest, load)
+}
+
+// FetchLocations reads the job locations, loading them on a miss
+func FetchLocations(ctx context.Context, dest interface{}, load LoadFunc) error {
+	return Fetch(ctx, LocationsCacheKey, LocationsCacheExpiration, dest, load)
+}
+
+// CacheApplications caches applications data
+func CacheApplications(ctx context.Context, applications interface{}) error {
+	return Set(ctx, ApplicationsCacheKey, applications, ApplicationsCacheExpiration)
+}
+
+// GetCachedApplications retrieves cached applications
+func GetCachedApplications(ctx context.Context, dest interface{}) error {
+	return Get(ctx, ApplicationsCacheKey, dest)
+}
+
+// CacheApplication caches individual application data
+func CacheApplication(ctx context.Context, appID int, application interface{}) error {
+	key := fmt.Sprintf(ApplicationCacheKey, appID)
+	return Set(ctx, key, application, ApplicationCacheExpiration)
+}
+
+// GetCachedApplication retrieves cached application
+func GetCachedApplication(ctx context.Context, appID int, dest interface{}) error {
+	key := fmt.Sprintf(ApplicationCacheKey, appID)
+	return Get(ctx, key, dest)
+}
+
+// InvalidateJobsCache invalidates every cached job listing
+func InvalidateJobsCache(ctx context.Context) error {
+	return InvalidateNamespace(ctx, JobsNamespace)
+}
+
+// InvalidateJobCache invalidates specific job cache
+func InvalidateJobCache(ctx context.Context, jobID int) error {
+	key := fmt.Sprintf(JobCacheKey, jobID)
+	return Delete(ctx, key)
+}
+
+// InvalidateLocationsCache invalidates locations cache
+func InvalidateLocationsCache(ctx context.Context) error {
+	return Delete(ctx, LocationsCacheKey)
+}
+
+// InvalidateApplicationsCache invalidates applications cache
+func InvalidateApplicationsCache(ctx context.Context) error {
+	return Delete(ctx, ApplicationsCacheKey)
+}
+
+// InvalidateApplicationCache invalidates specific application cache
+func InvalidateApplicationCache(ctx context.Context, appID int) error {
+	key := fmt.Sprintf(ApplicationCacheKey, appID)
+	return Delete(ctx, key)
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FallbackCache uses a shared remote cache and falls back to a local cache
+// while the remote one fails, so a Redis outage degrades caching to one
+// replica instead of disabling it. Values written during the outage are kept
+// locally for at most localTTL, since invalidations from other replicas do
+// not reach them.
+type FallbackCache struct {
+	local    *LocalCache
+	remote   Cache
+	localTTL time.Duration
+}
+
+var (
+	_ Cache     = (*FallbackCache)(nil)
+	_ Inspector = (*FallbackCache)(nil)
+)
+
+// NewFallbackCache creates a cache reading remote, or local when remote fails
+func NewFallbackCache(local *LocalCache, remote Cache, localTTL time.Duration) *FallbackCache {
+	return &FallbackCache{local: local, remote: remote, localTTL: localTTL}
+}
+
+// Get retrieves key from the remote cache, or from the local cache when the
+// remote one fails
+func (fc *FallbackCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := fc.remote.Get(ctx, key)
+	if err == nil || errors.Is(err, ErrMiss) || canceled(ctx) {
+		return value, err
+	}
+	return fc.local.Get(ctx, key)
+}
+
+// Set stores value in the remote cache, or in the local cache when the remote
+// one fails
+func (fc *FallbackCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	err := fc.remote.Set(ctx, key, value, expiration)
+	if err == nil || canceled(ctx) {
+		return err
+	}
+
+	localExpiration := fc.localTTL
+	if expiration > 0 && expiration < localExpiration {
+		localExpiration = expiration
+	}
+	return fc.local.Set(ctx, key, value, localExpiration)
+}
+
+// Delete removes keys from both caches, so values kept during an outage are
+// not served in the next one
+func (fc *FallbackCache) Delete(ctx context.Context, keys ...string) error {
+	fc.local.Delete(ctx, keys...)
+	return fc.remote.Delete(ctx, keys...)
+}
+
+// Keys returns up to limit keys starting with prefix from each cache
+func (fc *FallbackCache) Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error) {
+	keys, _ := fc.local.Keys(ctx, prefix, limit)
+
+	inspector, ok := fc.remote.(Inspector)
+	if !ok {
+		return keys, nil
+	}
+
+	remoteKeys, err := inspector.Keys(ctx, prefix, limit)
+	return append(keys, remoteKeys...), err
+}
+
+// DeletePrefix removes every key starting with prefix from both caches and
+// returns how many keys were removed from the remote cache
+func (fc *FallbackCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	fc.local.DeletePrefix(ctx, prefix)
+
+	inspector, ok := fc.remote.(Inspector)
+	if !ok {
+		return 0, nil
+	}
+	return inspector.DeletePrefix(ctx, prefix)
+}
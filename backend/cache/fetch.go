@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshTimeout bounds a background refresh, which outlives its request
+const refreshTimeout = 10 * time.Second
+
+// earlyRefreshBeta weights the probabilistic early refresh. Values above 1
+// refresh earlier, values below 1 later.
+const earlyRefreshBeta = 1.0
+
+var (
+	// staleTTL is how long an expired value is still served while it is
+	// refreshed in the background; zero disables stale-while-revalidate
+	staleTTL time.Duration
+
+	// earlyRefresh enables the probabilistic refresh of values close to expiry
+	earlyRefresh = true
+
+	// loads coalesces concurrent loads of the same key in this process
+	loads singleflight.Group
+
+	// refreshing holds the keys being refreshed in the background
+	refreshing sync.Map
+
+	cacheRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_refreshes_total",
+		Help: "Background cache refreshes by key prefix and trigger (early or stale).",
+	}, []string{"prefix", "trigger"})
+)
+
+// Refresh triggers
+const (
+	refreshEarly = "early"
+	refreshStale = "stale"
+)
+
+// withoutCacheKey marks contexts whose reads skip the cache
+type withoutCacheKey struct{}
+
+// WithoutCache returns a context whose fetches load the value directly,
+// neither reading nor filling the cache
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutCacheKey{}, true)
+}
+
+// LoadFunc loads the value of a key on a cache miss
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// fetchEntry is the cached form of a value read through Fetch
+type fetchEntry struct {
+	Value json.RawMessage `json:"v"`
+
+	// FreshUntil is when the value expires, in Unix milliseconds
+	FreshUntil int64 `json:"f"`
+
+	// Delta is how long the value took to load, in milliseconds
+	Delta int64 `json:"d"`
+}
+
+// shouldRefreshEarly decides whether to refresh a fresh entry ahead of its
+// expiry. The chance rises as expiry approaches and with slower loads, so
+// one request usually refreshes a hot key before every request misses it
+// at once ("XFetch").
+func (e fetchEntry) shouldRefreshEarly(now time.Time) bool {
+	gap := float64(e.Delta) * earlyRefreshBeta * -math.Log(1-rand.Float64())
+	return float64(now.UnixMilli())+gap >= float64(e.FreshUntil)
+}
+
+// Fetch reads key into dest, calling load on a miss and caching its result
+// for ttl. Concurrent misses of the same key share one load. Values close to
+// expiry are refreshed early in the background, and when stale-while-
+// revalidate is enabled an expired value is served while it is refreshed.
+// Errors from load are returned as is and nothing is cached. Fetch returns
+// the context error as soon as ctx is done. Contexts marked WithoutCache
+// call load directly. The fetch operation timeout bounds reading and
+// writing the cache, not load.
+func Fetch(ctx context.Context, key string, ttl time.Duration, dest interface{}, load LoadFunc) error {
+	if ctx.Value(withoutCacheKey{}) != nil {
+		value, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, dest)
+	}
+
+	getCtx, cancel := withOperationTimeout(ctx, opFetch)
+	raw, err := store.Get(getCtx, key)
+	cancel()
+
+	var entry fetchEntry
+	if err == nil && json.Unmarshal(raw, &entry) == nil {
+		now := time.Now()
+		fresh := now.UnixMilli() < entry.FreshUntil
+
+		switch {
+		case fresh && earlyRefresh && entry.shouldRefreshEarly(now):
+			refreshAsync(ctx, key, ttl, load, refreshEarly)
+		case !fresh && staleTTL > 0:
+			refreshAsync(ctx, key, ttl, load, refreshStale)
+		}
+
+		if fresh || staleTTL > 0 {
+			recordHit(key)
+			return json.Unmarshal(entry.Value, dest)
+		}
+	}
+
+	if err != nil && !errors.Is(err, ErrMiss) {
+		recordError(key)
+	} else {
+		recordMiss(key)
+	}
+
+	// The shared load is not canceled with the request that started it, so
+	// the other waiters still get its result; the query deadlines bound it.
+	// Each caller stops waiting when its own request ends.
+	results := loads.DoChan(key, func() (interface{}, error) {
+		return refresh(context.WithoutCancel(ctx), key, ttl, load)
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return result.Err
+		}
+		return json.Unmarshal(result.Val.([]byte), dest)
+	}
+}
+
+// refresh loads key and caches it, returning the encoded value. The entry
+// is kept for staleTTL past its expiry so it can be served stale.
+func refresh(ctx context.Context, key string, ttl time.Duration, load LoadFunc) ([]byte, error) {
+	start := time.Now()
+	value, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delta := time.Since(start)
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := json.Marshal(fetchEntry{
+		Value:      encoded,
+		FreshUntil: time.Now().Add(ttl).UnixMilli(),
+		Delta:      delta.Milliseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The value is still returned when it cannot be cached
+	setCtx, cancel := withOperationTimeout(ctx, opFetch)
+	defer cancel()
+	if err := store.Set(setCtx, key, entry, ttl+staleTTL); err != nil {
+		slog.WarnContext(ctx, "Failed to cache value", "key_prefix", keyPrefix(key), "error", err)
+	}
+
+	return encoded, nil
+}
+
+// refreshAsync refreshes key in the background unless a refresh of key is
+// already running. The refresh is detached from the request, so it completes
+// even when the client that triggered it disconnects.
+func refreshAsync(ctx context.Context, key string, ttl time.Duration, load LoadFunc, trigger string) {
+	if _, running := refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	cacheRefreshes.WithLabelValues(keyPrefix(key), trigger).Inc()
+
+	go func() {
+		defer refreshing.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+
+		_, err, _ := loads.Do(key, func() (interface{}, error) {
+			return refresh(ctx, key, ttl, load)
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "Background cache refresh failed", "key_prefix", keyPrefix(key), "trigger", trigger, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// invalidationChannel is the Redis channel on which instances announce the
+// keys they invalidated, so the others evict them from their local tier
+const invalidationChannel = "cache:invalidate"
+
+// Subscription health and reconnection timing
+const (
+	invalidationPingInterval = 30 * time.Second
+	minResubscribeDelay      = 100 * time.Millisecond
+	maxResubscribeDelay      = 30 * time.Second
+)
+
+var (
+	// tiered is the store when it has a local tier to keep in sync, else nil
+	tiered *TieredCache
+
+	// instanceID identifies the invalidations published by this instance
+	instanceID = newInstanceID()
+
+	// stopInvalidations stops the invalidation subscriber
+	stopInvalidations context.CancelFunc = func() {}
+
+	errNoPong = errors.New("no reply to ping on the invalidation subscription")
+)
+
+// invalidationMessage lists the keys and key prefixes an instance invalidated
+type invalidationMessage struct {
+	Origin   string   `json:"origin"`
+	Keys     []string `json:"keys,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// newInstanceID returns a random identifier for this process
+func newInstanceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// publishInvalidation tells the other instances to evict keys from their
+// local tier. Without a local tier there is nothing to keep in sync. When
+// publishing fails the other instances serve the old entries until their
+// local TTL ends.
+func publishInvalidation(ctx context.Context, keys ...string) {
+	if len(keys) > 0 {
+		publish(ctx, invalidationMessage{Origin: instanceID, Keys: keys})
+	}
+}
+
+// publishPrefixInvalidation tells the other instances to evict every key
+// starting with prefix from their local tier
+func publishPrefixInvalidation(ctx context.Context, prefix string) {
+	publish(ctx, invalidationMessage{Origin: instanceID, Prefixes: []string{prefix}})
+}
+
+// publish sends msg on the invalidation channel when there is a local tier to keep in sync
+func publish(ctx context.Context, msg invalidationMessage) {
+	if tiered == nil {
+		return
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, opPublish)
+	defer cancel()
+	if err := redisClient.Publish(ctx, invalidationChannel, payload).Err(); err != nil {
+		slog.WarnContext(ctx, "Failed to publish cache invalidation", "error", err)
+	}
+}
+
+// startInvalidationSubscriber evicts the keys invalidated by other instances
+// from the local tier until Close is called
+func startInvalidationSubscriber() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopInvalidations = cancel
+
+	go subscribeInvalidations(ctx)
+}
+
+// subscribeInvalidations keeps a subscription to the invalidation channel,
+// resubscribing with exponential backoff when it is interrupted. Messages
+// published while the subscription was down are lost, so the whole local
+// tier is flushed after every resubscription.
+func subscribeInvalidations(ctx context.Context) {
+	delay := minResubscribeDelay
+	for resync := false; ; resync = true {
+		subscribed, err := listenInvalidations(ctx, resync)
+		if ctx.Err() != nil {
+			return
+		}
+		if subscribed {
+			delay = minResubscribeDelay
+		}
+
+		slog.Warn("Cache invalidation subscription interrupted", "error", err, "retry_in", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxResubscribeDelay)
+	}
+}
+
+// listenInvalidations subscribes to the invalidation channel and applies its
+// messages until the subscription fails. It reports whether the subscription
+// was established.
+func listenInvalidations(ctx context.Context, resync bool) (bool, error) {
+	pubsub := redisClient.Subscribe(ctx, invalidationChannel)
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return false, err
+	}
+
+	if resync {
+		tiered.FlushLocal()
+		slog.Info("Cache invalidation subscription restored, local cache flushed")
+	}
+
+	// Ping when idle so a dead connection is noticed
+	awaitingPong := false
+	for {
+		msg, err := pubsub.ReceiveTimeout(ctx, invalidationPingInterval)
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				return true, err
+			}
+			if awaitingPong {
+				return true, errNoPong
+			}
+			if err := pubsub.Ping(ctx); err != nil {
+				return true, err
+			}
+			awaitingPong = true
+			continue
+		}
+
+		awaitingPong = false
+		if m, ok := msg.(*redis.Message); ok {
+			applyInvalidation(m.Payload)
+		}
+	}
+}
+
+// applyInvalidation evicts the keys of an invalidation message published by
+// another instance from the local tier
+func applyInvalidation(payload string) {
+	var msg invalidationMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		slog.Warn("Ignoring malformed cache invalidation", "error", err)
+		return
+	}
+
+	if msg.Origin == instanceID {
+		return
+	}
+
+	tiered.EvictLocal(msg.Keys...)
+	for _, prefix := range msg.Prefixes {
+		tiered.EvictLocalPrefix(prefix)
+	}
+}
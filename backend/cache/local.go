@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocalCache is an in-process cache bounded to maxEntries. Expired entries
+// are dropped when read, and the least recently used entry is evicted when
+// the cache is full.
+type LocalCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is the most recently used
+	entries    map[string]*list.Element
+}
+
+type localEntry struct {
+	key   string
+	value []byte
+
+	// expiresAt is zero for entries without expiration
+	expiresAt time.Time
+}
+
+var (
+	_ Cache     = (*LocalCache)(nil)
+	_ Inspector = (*LocalCache)(nil)
+)
+
+// NewLocalCache creates a local cache holding at most maxEntries entries
+func NewLocalCache(maxEntries int) *LocalCache {
+	return &LocalCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get retrieves the value of key
+func (lc *LocalCache) Get(ctx context.Context, key string) ([]byte, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	elem, ok := lc.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+
+	entry := elem.Value.(*localEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		lc.remove(elem)
+		return nil, ErrMiss
+	}
+
+	lc.order.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// Set stores value under key, evicting the least recently used entry when full
+func (lc *LocalCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+	if elem, ok := lc.entries[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		lc.order.MoveToFront(elem)
+		return nil
+	}
+
+	lc.entries[key] = lc.order.PushFront(&localEntry{key: key, value: value, expiresAt: expiresAt})
+	for lc.order.Len() > lc.maxEntries {
+		lc.remove(lc.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes keys
+func (lc *LocalCache) Delete(ctx context.Context, keys ...string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := lc.entries[key]; ok {
+			lc.remove(elem)
+		}
+	}
+
+	return nil
+}
+
+// Keys returns up to limit unexpired keys starting with prefix, most
+// recently used first
+func (lc *LocalCache) Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	now := time.Now()
+	var keys []KeyInfo
+	for elem := lc.order.Front(); elem != nil && len(keys) < limit; elem = elem.Next() {
+		entry := elem.Value.(*localEntry)
+		if !strings.HasPrefix(entry.key, prefix) {
+			continue
+		}
+
+		ttl := time.Duration(-1)
+		if !entry.expiresAt.IsZero() {
+			ttl = entry.expiresAt.Sub(now)
+			if ttl <= 0 {
+				continue
+			}
+		}
+		keys = append(keys, KeyInfo{Key: entry.key, Tier: TierLocal, TTLSeconds: ttlSeconds(ttl)})
+	}
+
+	return keys, nil
+}
+
+// DeletePrefix removes every key starting with prefix
+func (lc *LocalCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	removed := 0
+	for key, elem := range lc.entries {
+		if strings.HasPrefix(key, prefix) {
+			lc.remove(elem)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// Flush removes every entry
+func (lc *LocalCache) Flush() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.order.Init()
+	clear(lc.entries)
+}
+
+// Len returns the number of entries, including expired ones not yet dropped
+func (lc *LocalCache) Len() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	return lc.order.Len()
+}
+
+// remove drops elem from the cache. The caller must hold lc.mu.
+func (lc *LocalCache) remove(elem *list.Element) {
+	lc.order.Remove(elem)
+	delete(lc.entries, elem.Value.(*localEntry).key)
+}
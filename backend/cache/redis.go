@@ -2,238 +2,234 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"job-portal-backend/config"
+	"job-portal-backend/health"
+	"job-portal-backend/logger"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
-var (
-	redisClient *redis.Client
-	ctx         = context.Background()
-)
+var redisClient *redis.Client
 
-// CacheConfig represents cache configuration
-type CacheConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-}
+// healthCheckTimeout bounds the Redis ping of the health check
+const healthCheckTimeout = time.Second
 
-// InitRedis initializes Redis connection
-func InitRedis() {
-	// Get Redis configuration from environment
-	host := os.Getenv("REDIS_HOST")
-	if host == "" {
-		host = "localhost"
+// InitRedis initializes Redis connection from cfg.URL, or from the host,
+// port, password and database of cfg when no URL is set
+func InitRedis(cfg config.RedisConfig) {
+	options := &redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	}
-
-	port := os.Getenv("REDIS_PORT")
-	if port == "" {
-		port = "6379"
+	if cfg.URL != "" {
+		parsed, err := redis.ParseURL(cfg.URL)
+		if err != nil {
+			logger.Fatal("Invalid REDIS_URL", "error", err)
+		}
+		options = parsed
 	}
-
-	password := os.Getenv("REDIS_PASSWORD")
-	db := 0
+	options.PoolSize = cfg.PoolSize
+	options.DialTimeout = cfg.Timeout
+	options.ReadTimeout = cfg.Timeout
+	options.WriteTimeout = cfg.Timeout
 
 	// Create Redis client
-	redisClient = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", host, port),
-		Password: password,
-		DB:       db,
-		PoolSize: 10,
+	redisClient = redis.NewClient(options)
+
+	// The cache is optional, so a failing Redis degrades the service
+	health.Register(health.Checker{
+		Name:    "cache",
+		Check:   GetStats,
+		Timeout: healthCheckTimeout,
 	})
 
 	// Test connection
-	_, err := redisClient.Ping(ctx).Result()
+	_, err := redisClient.Ping(context.Background()).Result()
 	if err != nil {
-		log.Printf("Warning: Redis connection failed: %v", err)
-		log.Println("Continuing without Redis cache")
+		slog.Warn("Redis connection failed, continuing without Redis cache", "error", err)
 		redisClient = nil
 		return
 	}
 
-	log.Println("Redis cache initialized successfully")
-}
-
-// Set sets a key-value pair in cache
-func Set(key string, value interface{}, expiration time.Duration) error {
-	if redisClient == nil {
-		return fmt.Errorf("Redis not available")
-	}
-
-	jsonValue, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
+	redisClient.AddHook(tracingHook{})
 
-	return redisClient.Set(ctx, key, jsonValue, expiration).Err()
+	slog.Info("Redis cache initialized successfully")
 }
 
-// Get retrieves a value from cache
-func Get(key string, dest interface{}) error {
+// Close stops the invalidation subscriber and closes the Redis client
+func Close() error {
+	stopInvalidations()
+
 	if redisClient == nil {
-		return fmt.Errorf("Redis not available")
+		return nil
 	}
+	return redisClient.Close()
+}
 
-	val, err := redisClient.Get(ctx, key).Result()
-	if err != nil {
-		return err
-	}
+// Client returns the Redis client, or nil when Redis is unavailable
+func Client() *redis.Client {
+	return redisClient
+}
 
-	return json.Unmarshal([]byte(val), dest)
+// RedisCache stores entries in Redis, shared by every replica
+type RedisCache struct {
+	client *redis.Client
 }
 
-// Delete removes a key from cache
-func Delete(key string) error {
-	if redisClient == nil {
-		return fmt.Errorf("Redis not available")
-	}
+var (
+	_ Cache     = (*RedisCache)(nil)
+	_ Inspector = (*RedisCache)(nil)
+)
 
-	return redisClient.Del(ctx, key).Err()
+// NewRedisCache creates a cache backed by client
+func NewRedisCache(client *redis.Client) *RedisCache {
+	return &RedisCache{client: client}
 }
 
-// Exists checks if a key exists in cache
-func Exists(key string) bool {
-	if redisClient == nil {
-		return false
+// Get retrieves the value of key from Redis
+func (rc *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := rc.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrMiss
 	}
+	return val, err
+}
 
-	exists, err := redisClient.Exists(ctx, key).Result()
-	return err == nil && exists > 0
+// Set stores value under key in Redis
+func (rc *RedisCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	return rc.client.Set(ctx, key, value, expiration).Err()
 }
 
-// FlushAll clears all cache
-func FlushAll() error {
-	if redisClient == nil {
-		return fmt.Errorf("Redis not available")
+// Delete removes keys from Redis
+func (rc *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
 	}
-
-	return redisClient.FlushAll(ctx).Err()
+	return rc.client.Del(ctx, keys...).Err()
 }
 
-// GetStats returns Redis statistics
-func GetStats() map[string]interface{} {
-	if redisClient == nil {
-		return map[string]interface{}{
-			"status": "unavailable",
-		}
-	}
+// scanBatchSize is the number of keys requested per SCAN call
+const scanBatchSize = 100
 
-	info, err := redisClient.Info(ctx).Result()
-	if err != nil {
-		return map[string]interface{}{
-			"status": "error",
-			"error":  err.Error(),
-		}
+// Keys returns up to limit keys starting with prefix and their TTL. SCAN is
+// used instead of KEYS so that Redis is never blocked.
+func (rc *RedisCache) Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error) {
+	var keys []string
+	iter := rc.client.Scan(ctx, 0, scanPattern(prefix), scanBatchSize).Iterator()
+	for len(keys) < limit && iter.Next(ctx) {
+		keys = append(keys, iter.Val())
 	}
-
-	return map[string]interface{}{
-		"status": "available",
-		"info":   info,
+	if err := iter.Err(); err != nil {
+		return nil, err
 	}
-}
-
-// Cache keys for different data types
-const (
-	// Job cache keys
-	JobsCacheKey      = "jobs:all"
-	JobCacheKey       = "job:%d"
-	LocationsCacheKey = "locations:all"
-
-	// Application cache keys
-	ApplicationsCacheKey = "applications:all"
-	ApplicationCacheKey  = "application:%d"
-
-	// Cache expiration times
-	JobsCacheExpiration         = 5 * time.Minute
-	JobCacheExpiration          = 10 * time.Minute
-	LocationsCacheExpiration    = 30 * time.Minute
-	ApplicationsCacheExpiration = 2 * time.Minute
-	ApplicationCacheExpiration  = 5 * time.Minute
-)
-
-// CacheJobs caches jobs data
-func CacheJobs(jobs interface{}) error {
-	return Set(JobsCacheKey, jobs, JobsCacheExpiration)
-}
-
-// GetCachedJobs retrieves cached jobs
-func GetCachedJobs(dest interface{}) error {
-	return Get(JobsCacheKey, dest)
-}
-
-// CacheJob caches individual job data
-func CacheJob(jobID int, job interface{}) error {
-	key := fmt.Sprintf(JobCacheKey, jobID)
-	return Set(key, job, JobCacheExpiration)
-}
 
-// GetCachedJob retrieves cached job
-func GetCachedJob(jobID int, dest interface{}) error {
-	key := fmt.Sprintf(JobCacheKey, jobID)
-	return Get(key, dest)
-}
+	pipe := rc.client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
 
-// CacheLocations caches locations data
-func CacheLocations(locations interface{}) error {
-	return Set(LocationsCacheKey, locations, LocationsCacheExpiration)
-}
+	infos := make([]KeyInfo, 0, len(keys))
+	for i, key := range keys {
+		// Keys that expired between SCAN and PTTL report -2
+		if ttls[i].Val() == -2 {
+			continue
+		}
+		infos = append(infos, KeyInfo{Key: key, Tier: TierRedis, TTLSeconds: ttlSeconds(ttls[i].Val())})
+	}
 
-// GetCachedLocations retrieves cached locations
-func GetCachedLocations(dest interface{}) error {
-	return Get(LocationsCacheKey, dest)
+	return infos, nil
 }
 
-// CacheApplications caches applications data
-func CacheApplications(applications interface{}) error {
-	return Set(ApplicationsCacheKey, applications, ApplicationsCacheExpiration)
-}
+// DeletePrefix removes every key starting with prefix in batches of SCAN results
+func (rc *RedisCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	removed := 0
+	iter := rc.client.Scan(ctx, 0, scanPattern(prefix), scanBatchSize).Iterator()
 
-// GetCachedApplications retrieves cached applications
-func GetCachedApplications(dest interface{}) error {
-	return Get(ApplicationsCacheKey, dest)
-}
+	batch := make([]string, 0, scanBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := rc.client.Unlink(ctx, batch...).Result()
+		removed += int(n)
+		batch = batch[:0]
+		return err
+	}
 
-// CacheApplication caches individual application data
-func CacheApplication(appID int, application interface{}) error {
-	key := fmt.Sprintf(ApplicationCacheKey, appID)
-	return Set(key, application, ApplicationCacheExpiration)
-}
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == scanBatchSize {
+			if err := flush(); err != nil {
+				return removed, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return removed, err
+	}
 
-// GetCachedApplication retrieves cached application
-func GetCachedApplication(appID int, dest interface{}) error {
-	key := fmt.Sprintf(ApplicationCacheKey, appID)
-	return Get(key, dest)
+	return removed, flush()
 }
 
-// InvalidateJobsCache invalidates jobs cache
-func InvalidateJobsCache() error {
-	return Delete(JobsCacheKey)
+// scanPattern returns the SCAN pattern matching keys that start with prefix
+func scanPattern(prefix string) string {
+	var b strings.Builder
+	for _, r := range prefix {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString("*")
+	return b.String()
 }
 
-// InvalidateJobCache invalidates specific job cache
-func InvalidateJobCache(jobID int) error {
-	key := fmt.Sprintf(JobCacheKey, jobID)
-	return Delete(key)
-}
+// errRedisUnavailable is reported by the health check, which is public, so
+// it must not carry the Redis address found in connection errors
+var errRedisUnavailable = errors.New("Redis not available")
 
-// InvalidateLocationsCache invalidates locations cache
-func InvalidateLocationsCache() error {
-	return Delete(LocationsCacheKey)
-}
+// GetStats pings Redis and returns the ping latency and connection pool
+// statistics, or an error when Redis is unreachable or was disabled at
+// startup. It backs the public health check, so the Redis address is left
+// out and connection errors are only logged.
+func GetStats(ctx context.Context) (map[string]interface{}, error) {
+	if redisClient == nil {
+		return nil, errRedisUnavailable
+	}
 
-// InvalidateApplicationsCache invalidates applications cache
-func InvalidateApplicationsCache() error {
-	return Delete(ApplicationsCacheKey)
-}
+	start := time.Now()
+	err := redisClient.Ping(ctx).Err()
+	latency := time.Since(start)
+
+	pool := redisClient.PoolStats()
+	stats := map[string]interface{}{
+		"ping_latency": latency.String(),
+		"pool": map[string]interface{}{
+			"hits":        pool.Hits,
+			"misses":      pool.Misses,
+			"timeouts":    pool.Timeouts,
+			"total_conns": pool.TotalConns,
+			"idle_conns":  pool.IdleConns,
+			"stale_conns": pool.StaleConns,
+		},
+	}
 
-// InvalidateApplicationCache invalidates specific application cache
-func InvalidateApplicationCache(appID int) error {
-	key := fmt.Sprintf(ApplicationCacheKey, appID)
-	return Delete(key)
+	if err != nil {
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		slog.Warn("Redis health check failed", "error", err)
+		return stats, errRedisUnavailable
+	}
+	return stats, nil
 }
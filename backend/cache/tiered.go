@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TieredCache keeps a local L1 cache in front of a shared L2 cache. Entries
+// are kept locally for at most localTTL, which bounds how stale a replica can
+// be after another replica changes the shared entry, unless the change is
+// announced on the invalidation channel. When L2 fails, the local entries
+// keep being served.
+type TieredCache struct {
+	local    *LocalCache
+	remote   Cache
+	localTTL time.Duration
+}
+
+var (
+	_ Cache     = (*TieredCache)(nil)
+	_ Inspector = (*TieredCache)(nil)
+)
+
+// NewTieredCache creates a cache reading local before remote
+func NewTieredCache(local *LocalCache, remote Cache, localTTL time.Duration) *TieredCache {
+	return &TieredCache{local: local, remote: remote, localTTL: localTTL}
+}
+
+// Get retrieves key from the local cache, then from the remote cache,
+// keeping remote hits locally
+func (tc *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := tc.local.Get(ctx, key); !errors.Is(err, ErrMiss) {
+		return value, err
+	}
+
+	value, err := tc.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	tc.local.Set(ctx, key, value, tc.localTTL)
+	return value, nil
+}
+
+// Set stores value in both caches. The local entry is kept even when the
+// remote cache fails.
+func (tc *TieredCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	localExpiration := tc.localTTL
+	if expiration > 0 && expiration < localExpiration {
+		localExpiration = expiration
+	}
+
+	tc.local.Set(ctx, key, value, localExpiration)
+	return tc.remote.Set(ctx, key, value, expiration)
+}
+
+// Delete removes keys from both caches
+func (tc *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	tc.local.Delete(ctx, keys...)
+	return tc.remote.Delete(ctx, keys...)
+}
+
+// Keys returns up to limit keys starting with prefix from each tier
+func (tc *TieredCache) Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error) {
+	keys, _ := tc.local.Keys(ctx, prefix, limit)
+
+	inspector, ok := tc.remote.(Inspector)
+	if !ok {
+		return keys, nil
+	}
+
+	remoteKeys, err := inspector.Keys(ctx, prefix, limit)
+	return append(keys, remoteKeys...), err
+}
+
+// DeletePrefix removes every key starting with prefix from both tiers and
+// returns how many keys were removed from the remote tier
+func (tc *TieredCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	tc.local.DeletePrefix(ctx, prefix)
+
+	inspector, ok := tc.remote.(Inspector)
+	if !ok {
+		return 0, nil
+	}
+	return inspector.DeletePrefix(ctx, prefix)
+}
+
+// EvictLocalPrefix removes every key starting with prefix from the local cache only
+func (tc *TieredCache) EvictLocalPrefix(prefix string) {
+	tc.local.DeletePrefix(context.Background(), prefix)
+}
+
+// EvictLocal removes keys from the local cache only, so they are read again
+// from the remote cache
+func (tc *TieredCache) EvictLocal(keys ...string) {
+	tc.local.Delete(context.Background(), keys...)
+}
+
+// FlushLocal empties the local cache
+func (tc *TieredCache) FlushLocal() {
+	tc.local.Flush()
+}
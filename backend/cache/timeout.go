@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Cache operations whose deadline can be overridden
+const (
+	opGet        = "get"
+	opSet        = "set"
+	opFetch      = "fetch"
+	opInvalidate = "invalidate"
+	opPublish    = "publish"
+)
+
+var (
+	// operationTimeouts overrides the deadline of cache operations by name.
+	// Operations without one are only bounded by the Redis timeout.
+	operationTimeouts map[string]time.Duration
+
+	// errOperationTimeout ends contexts whose operation deadline passed
+	errOperationTimeout = errors.New("cache operation timed out")
+)
+
+// withOperationTimeout bounds ctx by the deadline of the cache operation op,
+// when one is configured
+func withOperationTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	if timeout, ok := operationTimeouts[op]; ok {
+		return context.WithTimeoutCause(ctx, timeout, errOperationTimeout)
+	}
+	return ctx, func() {}
+}
+
+// canceled reports whether ctx ended for the caller, rather than because a
+// cache operation ran past its deadline, so there is no point falling back
+func canceled(ctx context.Context) bool {
+	return ctx.Err() != nil && !errors.Is(context.Cause(ctx), errOperationTimeout)
+}
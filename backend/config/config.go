@@ -0,0 +1,583 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environments of the application
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+)
+
+// exampleMagicLinkSecret is the placeholder env.example used to ship
+const exampleMagicLinkSecret = "change-me-to-a-long-random-string"
+
+// Config is the typed application configuration. Every field can be set in
+// the optional YAML file under its yaml key, and overridden by the
+// environment variable in its env tag. Tracing is configured separately
+// through the standard OTEL_* variables.
+type Config struct {
+	// Environment is "development" or "production". Production requires the
+	// secrets that must be shared by every replica.
+	Environment string `yaml:"environment" env:"APP_ENV"`
+
+	Server    ServerConfig    `yaml:"server"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Security  SecurityConfig  `yaml:"security"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Cache     CacheConfig     `yaml:"cache"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Log       LogConfig       `yaml:"log"`
+	Errors    ErrorsConfig    `yaml:"errors"`
+	Mail      MailConfig      `yaml:"mail"`
+
+	// MagicLinkSecret signs candidate magic links. It is required outside
+	// development; when empty an ephemeral secret is generated and links stop
+	// working after a restart
+	MagicLinkSecret string `yaml:"magic_link_secret" env:"MAGIC_LINK_SECRET"`
+
+	// FrontendURL is the base URL of links sent to candidates
+	FrontendURL string `yaml:"frontend_url" env:"FRONTEND_URL"`
+
+	// DefaultLocale is used when Accept-Language has no supported locale
+	DefaultLocale string `yaml:"default_locale" env:"DEFAULT_LOCALE"`
+
+	// AdminAPIKey authenticates the admin endpoints; when empty they are disabled
+	AdminAPIKey string `yaml:"admin_api_key" env:"ADMIN_API_KEY"`
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	Port           string   `yaml:"port" env:"PORT"`
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+
+	// ShutdownDelay keeps serving after readiness turns not_ready so load
+	// balancers can stop routing here before connections are drained
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY"`
+
+	// ShutdownTimeout bounds draining in-flight requests and background work
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+// CORSConfig holds the cross-origin settings. Origins are exact origins such
+// as https://jobportal.example.com, patterns with a leading wildcard label
+// such as https://*.example.com or https://job-portal-frontend-*.vercel.app,
+// or * for any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE"`
+
+	// Tenants add origins for requests to their own API hosts, set in the YAML file only
+	Tenants []CORSTenant `yaml:"tenants"`
+}
+
+// CORSTenant allows extra origins for requests whose Host matches Hosts,
+// for example a white-label frontend calling api.partner.example.com
+type CORSTenant struct {
+	Name    string   `yaml:"name"`
+	Hosts   []string `yaml:"hosts"`
+	Origins []string `yaml:"origins"`
+}
+
+// SecurityConfig holds request body limits and security header settings
+type SecurityConfig struct {
+	// MaxBodyBytes limits JSON and form bodies, MaxUploadBytes the CV upload routes
+	MaxBodyBytes   int `yaml:"max_body_bytes" env:"MAX_BODY_BYTES"`
+	MaxUploadBytes int `yaml:"max_upload_bytes" env:"MAX_UPLOAD_BYTES"`
+
+	// HSTSMaxAge is sent on HTTPS requests; zero disables HSTS
+	HSTSMaxAge     time.Duration `yaml:"hsts_max_age" env:"HSTS_MAX_AGE"`
+	ReferrerPolicy string        `yaml:"referrer_policy" env:"REFERRER_POLICY"`
+}
+
+// DatabaseConfig holds the PostgreSQL connection settings. URL takes
+// precedence over the individual fields.
+type DatabaseConfig struct {
+	URL string `yaml:"url" env:"DATABASE_URL"`
+
+	// ReplicaURLs are read-only replicas serving read queries, which use
+	// the primary when there are none
+	ReplicaURLs []string `yaml:"replica_urls" env:"DATABASE_REPLICA_URLS"`
+
+	// ReadYourWritesWindow sends a client's reads to the primary for this
+	// long after its own writes, so it never sees replication lag
+	ReadYourWritesWindow time.Duration `yaml:"read_your_writes_window" env:"DB_READ_YOUR_WRITES_WINDOW"`
+
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+
+	MaxOpenConns       int `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns       int `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	MaxLifetimeMinutes int `yaml:"max_lifetime_minutes" env:"DB_MAX_LIFETIME_MINUTES"`
+	MaxIdleTimeMinutes int `yaml:"max_idle_time_minutes" env:"DB_MAX_IDLE_TIME_MINUTES"`
+
+	// ReadTimeout bounds SELECT queries and WriteTimeout every other statement
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"DB_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"DB_WRITE_TIMEOUT"`
+
+	// QueryTimeouts overrides the deadline of queries by name, such as
+	// count_jobs, set in the YAML file only
+	QueryTimeouts map[string]time.Duration `yaml:"query_timeouts"`
+}
+
+// RedisConfig holds the Redis connection settings. URL takes precedence over
+// the individual fields.
+type RedisConfig struct {
+	URL      string `yaml:"url" env:"REDIS_URL"`
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     string `yaml:"port" env:"REDIS_PORT"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+	PoolSize int    `yaml:"pool_size" env:"REDIS_POOL_SIZE"`
+
+	// Timeout bounds connecting and every Redis command, so a slow Redis
+	// degrades the cache and rate limiting instead of blocking requests
+	Timeout time.Duration `yaml:"timeout" env:"REDIS_TIMEOUT"`
+}
+
+// CacheConfig selects the cache store. Mode "redis" falls back to the local
+// store when Redis is unavailable, at startup or later, "local" keeps the
+// cache in process and "tiered" puts the local store in front of Redis.
+type CacheConfig struct {
+	Mode string `yaml:"mode" env:"CACHE_MODE"`
+
+	// LocalMaxEntries bounds the local store, least recently used entries are evicted first
+	LocalMaxEntries int `yaml:"local_max_entries" env:"CACHE_LOCAL_MAX_ENTRIES"`
+
+	// LocalTTL caps how long the tiered mode keeps an entry locally, and how
+	// long the redis mode keeps one written while Redis is down
+	LocalTTL time.Duration `yaml:"local_ttl" env:"CACHE_LOCAL_TTL"`
+
+	// StaleTTL is how long an expired value is served while it is refreshed
+	// in the background; zero disables stale-while-revalidate
+	StaleTTL time.Duration `yaml:"stale_ttl" env:"CACHE_STALE_TTL"`
+
+	// EarlyRefresh refreshes hot values shortly before they expire
+	EarlyRefresh bool `yaml:"early_refresh" env:"CACHE_EARLY_REFRESH"`
+
+	// WarmUp loads the locations, the first WarmUpPages listing pages and
+	// the WarmUpJobs most applied jobs into the cache at startup
+	WarmUp      bool `yaml:"warm_up" env:"CACHE_WARM_UP"`
+	WarmUpPages int  `yaml:"warm_up_pages" env:"CACHE_WARM_UP_PAGES"`
+	WarmUpJobs  int  `yaml:"warm_up_jobs" env:"CACHE_WARM_UP_JOBS"`
+
+	// OperationTimeouts overrides the deadline of cache operations by name
+	// (get, set, fetch, invalidate or publish), set in the YAML file only
+	OperationTimeouts map[string]time.Duration `yaml:"operation_timeouts"`
+}
+
+// RateLimitConfig selects the rate limiter store and policy file
+type RateLimitConfig struct {
+	Store      string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	PolicyFile string `yaml:"policy_file" env:"RATE_LIMIT_CONFIG"`
+}
+
+// LogConfig holds the logging settings
+type LogConfig struct {
+	Level     string `yaml:"level" env:"LOG_LEVEL"`
+	Format    string `yaml:"format" env:"LOG_FORMAT"`
+	RedactPII bool   `yaml:"redact_pii" env:"LOG_REDACT_PII"`
+}
+
+// ErrorsConfig holds the error response settings
+type ErrorsConfig struct {
+	Format      string `yaml:"format" env:"ERROR_FORMAT"`
+	TypeBaseURI string `yaml:"type_base_uri" env:"PROBLEM_TYPE_BASE_URI"`
+}
+
+// MailConfig holds the SMTP settings. Emails are written to the log when Host is empty.
+type MailConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+}
+
+// Default returns the configuration used for settings that are not set
+func Default() Config {
+	return Config{
+		Environment: EnvironmentProduction,
+		Server: ServerConfig{
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{
+				"http://localhost:5173",
+				"http://127.0.0.1:5173",
+				"https://job-portal-frontend.vercel.app",
+				"https://job-portal-frontend-*.vercel.app",
+			},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{
+				"Origin", "Content-Type", "Accept", "Accept-Language", "Authorization",
+				"X-API-Key", "X-Request-ID", "traceparent", "tracestate",
+			},
+			ExposedHeaders: []string{
+				"X-Request-ID", "Content-Language", "Retry-After",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+			},
+			AllowCredentials: true,
+			MaxAge:           12 * time.Hour,
+		},
+		Security: SecurityConfig{
+			MaxBodyBytes:   1 << 20,
+			MaxUploadBytes: 6 << 20,
+			HSTSMaxAge:     180 * 24 * time.Hour,
+			ReferrerPolicy: "strict-origin-when-cross-origin",
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:         25,
+			MaxIdleConns:         5,
+			MaxLifetimeMinutes:   5,
+			MaxIdleTimeMinutes:   5,
+			ReadTimeout:          5 * time.Second,
+			WriteTimeout:         10 * time.Second,
+			ReadYourWritesWindow: 5 * time.Second,
+		},
+		Redis: RedisConfig{
+			Host:     "localhost",
+			Port:     "6379",
+			PoolSize: 10,
+			Timeout:  500 * time.Millisecond,
+		},
+		Cache: CacheConfig{
+			Mode:            "redis",
+			LocalMaxEntries: 10000,
+			LocalTTL:        30 * time.Second,
+			StaleTTL:        time.Minute,
+			EarlyRefresh:    true,
+			WarmUp:          true,
+			WarmUpPages:     3,
+			WarmUpJobs:      20,
+		},
+		RateLimit: RateLimitConfig{
+			Store: "redis",
+		},
+		Log: LogConfig{
+			Level:     "info",
+			Format:    "json",
+			RedactPII: true,
+		},
+		Errors: ErrorsConfig{
+			Format: "compat",
+		},
+		Mail: MailConfig{
+			Port: "587",
+			From: "no-reply@jobportal.local",
+		},
+		FrontendURL:   "http://localhost:5173",
+		DefaultLocale: "id",
+	}
+}
+
+// Development reports whether the configuration is for local development
+func (c *Config) Development() bool {
+	return strings.EqualFold(c.Environment, EnvironmentDevelopment)
+}
+
+// ValidationError lists every invalid setting found at startup
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// add records a problem
+func (e *ValidationError) add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// Validate checks every setting and returns a ValidationError listing all
+// problems, or nil
+func (c *Config) Validate() error {
+	errs := &ValidationError{}
+
+	validateOneOf(errs, "APP_ENV", c.Environment, EnvironmentDevelopment, EnvironmentProduction)
+
+	// Server
+	if c.Server.Port == "" {
+		errs.add("PORT is required")
+	} else {
+		validatePort(errs, "PORT", c.Server.Port)
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs.add("TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_DELAY", c.Server.ShutdownDelay},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	} {
+		if timeout.value < 0 {
+			errs.add("%s must not be negative", timeout.name)
+		}
+	}
+
+	// CORS
+	for _, origin := range c.CORS.AllowedOrigins {
+		validateOrigin(errs, "CORS_ALLOWED_ORIGINS", origin, c.CORS.AllowCredentials)
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		errs.add("CORS_ALLOWED_METHODS must not be empty")
+	}
+	if c.CORS.MaxAge < 0 {
+		errs.add("CORS_MAX_AGE must not be negative")
+	}
+	for i, tenant := range c.CORS.Tenants {
+		name := fmt.Sprintf("cors.tenants[%d]", i)
+		if tenant.Name == "" {
+			errs.add("%s: name is required", name)
+		} else {
+			name = "cors.tenants." + tenant.Name
+		}
+		if len(tenant.Hosts) == 0 {
+			errs.add("%s: hosts must not be empty", name)
+		}
+		if len(tenant.Origins) == 0 {
+			errs.add("%s: origins must not be empty", name)
+		}
+		for _, origin := range tenant.Origins {
+			validateOrigin(errs, name, origin, c.CORS.AllowCredentials)
+		}
+	}
+
+	// Security
+	if c.Security.MaxBodyBytes < 1 {
+		errs.add("MAX_BODY_BYTES must be at least 1")
+	}
+	// The CV alone may be 5MB, plus the other form fields
+	if c.Security.MaxUploadBytes < 5<<20 {
+		errs.add("MAX_UPLOAD_BYTES must be at least 5242880 (the 5MB CV limit)")
+	}
+	if c.Security.HSTSMaxAge < 0 {
+		errs.add("HSTS_MAX_AGE must not be negative")
+	}
+
+	// Database
+	if c.Database.URL == "" {
+		for _, field := range []struct {
+			name  string
+			value string
+		}{
+			{"DB_HOST", c.Database.Host},
+			{"DB_PORT", c.Database.Port},
+			{"DB_USER", c.Database.User},
+			{"DB_PASSWORD", c.Database.Password},
+			{"DB_NAME", c.Database.Name},
+		} {
+			if field.value == "" {
+				errs.add("%s is required when DATABASE_URL is not set", field.name)
+			}
+		}
+		if c.Database.Port != "" {
+			validatePort(errs, "DB_PORT", c.Database.Port)
+		}
+	}
+	for _, replica := range c.Database.ReplicaURLs {
+		if u, err := url.Parse(replica); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+			errs.add("DATABASE_REPLICA_URLS must list postgres:// URLs")
+			break
+		}
+	}
+	if c.Database.ReadYourWritesWindow < 0 {
+		errs.add("DB_READ_YOUR_WRITES_WINDOW must not be negative")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		errs.add("DB_MAX_OPEN_CONNS must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs.add("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+	if c.Database.MaxLifetimeMinutes < 0 {
+		errs.add("DB_MAX_LIFETIME_MINUTES must not be negative")
+	}
+	if c.Database.MaxIdleTimeMinutes < 0 {
+		errs.add("DB_MAX_IDLE_TIME_MINUTES must not be negative")
+	}
+	if c.Database.ReadTimeout <= 0 {
+		errs.add("DB_READ_TIMEOUT must be positive")
+	}
+	if c.Database.WriteTimeout <= 0 {
+		errs.add("DB_WRITE_TIMEOUT must be positive")
+	}
+	for name, timeout := range c.Database.QueryTimeouts {
+		if timeout <= 0 {
+			errs.add("database.query_timeouts.%s must be positive", name)
+		}
+	}
+
+	// Redis
+	if c.Redis.URL != "" {
+		if u, err := url.Parse(c.Redis.URL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			errs.add("REDIS_URL must be a redis:// or rediss:// URL")
+		}
+	} else {
+		validatePort(errs, "REDIS_PORT", c.Redis.Port)
+	}
+	if c.Redis.DB < 0 {
+		errs.add("REDIS_DB must not be negative")
+	}
+	if c.Redis.PoolSize < 1 {
+		errs.add("REDIS_POOL_SIZE must be at least 1")
+	}
+	if c.Redis.Timeout <= 0 {
+		errs.add("REDIS_TIMEOUT must be positive")
+	}
+
+	// Cache
+	validateOneOf(errs, "CACHE_MODE", c.Cache.Mode, "redis", "local", "tiered")
+	if c.Cache.LocalMaxEntries < 1 {
+		errs.add("CACHE_LOCAL_MAX_ENTRIES must be at least 1")
+	}
+	if c.Cache.LocalTTL <= 0 {
+		errs.add("CACHE_LOCAL_TTL must be positive")
+	}
+	if c.Cache.StaleTTL < 0 {
+		errs.add("CACHE_STALE_TTL must not be negative")
+	}
+	if c.Cache.WarmUpPages < 0 {
+		errs.add("CACHE_WARM_UP_PAGES must not be negative")
+	}
+	if c.Cache.WarmUpJobs < 0 {
+		errs.add("CACHE_WARM_UP_JOBS must not be negative")
+	}
+	for name, timeout := range c.Cache.OperationTimeouts {
+		validateOneOf(errs, "cache.operation_timeouts key", name, "get", "set", "fetch", "invalidate", "publish")
+		if timeout <= 0 {
+			errs.add("cache.operation_timeouts.%s must be positive", name)
+		}
+	}
+
+	// Rate limiting
+	validateOneOf(errs, "RATE_LIMIT_STORE", c.RateLimit.Store, "memory", "redis")
+	if c.RateLimit.PolicyFile != "" {
+		if _, err := os.Stat(c.RateLimit.PolicyFile); err != nil {
+			errs.add("RATE_LIMIT_CONFIG: %v", err)
+		}
+	}
+
+	// Logging and errors
+	validateOneOf(errs, "LOG_LEVEL", c.Log.Level, "debug", "info", "warn", "error")
+	validateOneOf(errs, "LOG_FORMAT", c.Log.Format, "json", "text")
+	validateOneOf(errs, "ERROR_FORMAT", c.Errors.Format, "compat", "problem")
+	if c.Errors.TypeBaseURI != "" {
+		validateURL(errs, "PROBLEM_TYPE_BASE_URI", c.Errors.TypeBaseURI)
+	}
+	validateOneOf(errs, "DEFAULT_LOCALE", c.DefaultLocale, "id", "en")
+
+	// Mail
+	if c.Mail.Host != "" {
+		validatePort(errs, "SMTP_PORT", c.Mail.Port)
+	}
+	if !strings.Contains(c.Mail.From, "@") {
+		errs.add("SMTP_FROM must be an email address")
+	}
+
+	validateURL(errs, "FRONTEND_URL", c.FrontendURL)
+
+	// A short admin key could be guessed
+	if c.AdminAPIKey != "" && len(c.AdminAPIKey) < 32 {
+		errs.add("ADMIN_API_KEY must be at least 32 characters")
+	}
+
+	// Magic links must verify on every replica and after restarts, and a
+	// short or published secret lets anyone forge them
+	switch {
+	case c.MagicLinkSecret == "" && !c.Development():
+		errs.add("MAGIC_LINK_SECRET is required outside development")
+	case c.MagicLinkSecret != "" && len(c.MagicLinkSecret) < 32:
+		errs.add("MAGIC_LINK_SECRET must be at least 32 characters")
+	case c.MagicLinkSecret == exampleMagicLinkSecret:
+		errs.add("MAGIC_LINK_SECRET must not be the example value from env.example")
+	}
+
+	if len(errs.Problems) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validatePort checks that value is a TCP port number
+func validatePort(errs *ValidationError, name, value string) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		errs.add("%s must be a port number between 1 and 65535, got %q", name, value)
+	}
+}
+
+// validateOneOf checks that value is one of allowed, ignoring case
+func validateOneOf(errs *ValidationError, name, value string, allowed ...string) {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return
+		}
+	}
+	errs.add("%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+}
+
+// wildcardOriginLabel matches the wildcard first label of an origin pattern,
+// * or a prefix followed by *
+var wildcardOriginLabel = regexp.MustCompile(`^([a-z]+://)([a-z0-9-]*)\*\.`)
+
+// validateOrigin checks that value is *, an origin, or an origin whose host
+// starts with a * or prefix* wildcard label
+func validateOrigin(errs *ValidationError, name, value string, credentials bool) {
+	if value == "*" {
+		if credentials {
+			errs.add("%s: * cannot be combined with CORS_ALLOW_CREDENTIALS", name)
+		}
+		return
+	}
+
+	u, err := url.Parse(wildcardOriginLabel.ReplaceAllString(strings.ToLower(value), "${1}${2}wildcard."))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || strings.Contains(u.Host, "*") {
+		errs.add("%s: %q must look like https://example.com, https://*.example.com or https://app-*.example.com", name, value)
+	}
+}
+
+// validateURL checks that value is an absolute http or https URL
+func validateURL(errs *ValidationError, name, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add("%s must be an absolute http or https URL, got %q", name, value)
+	}
+}
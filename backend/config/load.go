@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from, in increasing precedence, the defaults,
+// the YAML file at path (or CONFIG_FILE when path is empty), a .env file and
+// the environment. The YAML file and .env are optional. Environment variables
+// in the YAML file are expanded, so secrets can be written as ${DB_PASSWORD}.
+//
+// The returned error lists every invalid setting; the configuration is
+// returned with it so that logging can still be set up.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	errs := &ValidationError{}
+
+	// Real environment variables win over .env, which only fills the gaps
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs.add(".env: %v", err)
+	}
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			errs.add("%s: %s", path, strings.ReplaceAll(err.Error(), "\n", " "))
+		}
+	}
+
+	applyEnv(reflect.ValueOf(&cfg).Elem(), errs)
+
+	if err := cfg.Validate(); err != nil {
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			errs.Problems = append(errs.Problems, invalid.Problems...)
+		}
+	}
+
+	if len(errs.Problems) > 0 {
+		return &cfg, errs
+	}
+	return &cfg, nil
+}
+
+// loadFile decodes the YAML file at path into cfg, rejecting unknown keys
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(os.ExpandEnv(string(data))))
+	decoder.KnownFields(true)
+	// An empty file decodes to io.EOF and leaves the defaults in place
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// applyEnv overrides the fields of v that have an env tag with the variables
+// that are set, recording values that cannot be parsed
+func applyEnv(v reflect.Value, errs *ValidationError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		name, ok := t.Field(i).Tag.Lookup("env")
+		if !ok {
+			if field.Kind() == reflect.Struct {
+				applyEnv(field, errs)
+			}
+			continue
+		}
+
+		value, set := os.LookupEnv(name)
+		if !set {
+			continue
+		}
+		if err := setField(field, strings.TrimSpace(value)); err != nil {
+			errs.add("%s: %v", name, err)
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField parses value into field according to its type
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, use a value like 30s or 2m", value)
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
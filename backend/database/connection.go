@@ -1,114 +1,124 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"job-portal-backend/config"
+	"job-portal-backend/health"
+	"job-portal-backend/logger"
+	"log/slog"
+	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
 var DB *sql.DB
 
-func InitDB() {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file:", err)
-	}
-
+func InitDB(cfg config.DatabaseConfig) {
 	var err error
 
 	// Check if DATABASE_URL is set (Railway deployment)
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL != "" {
+	if cfg.URL != "" {
 		// Use DATABASE_URL for Railway deployment
-		DB, err = sql.Open("postgres", databaseURL)
+		DB, err = sql.Open("postgres", cfg.URL)
 		if err != nil {
-			log.Fatal("Error opening database with DATABASE_URL:", err)
+			logger.Fatal("Error opening database with DATABASE_URL", "error", err)
 		}
 	} else {
-		// Use .env file variables for local development
-		host := os.Getenv("DB_HOST")
-		port := os.Getenv("DB_PORT")
-		user := os.Getenv("DB_USER")
-		password := os.Getenv("DB_PASSWORD")
-		dbname := os.Getenv("DB_NAME")
-
-		// Validate required environment variables
-		if host == "" || port == "" || user == "" || password == "" || dbname == "" {
-			log.Fatal("Missing required database environment variables in .env file")
-		}
-
-		// String koneksi PostgreSQL
+		// String koneksi PostgreSQL, field wajib sudah divalidasi oleh config
 		psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			host, port, user, password, dbname)
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
 
 		// Buka koneksi database
 		DB, err = sql.Open("postgres", psqlInfo)
 		if err != nil {
-			log.Fatal("Error opening database:", err)
+			logger.Fatal("Error opening database", "error", err)
 		}
 	}
 
-	// Configure connection pool
-	configureConnectionPool()
+	// Configure connection pool and query deadlines
+	configureConnectionPool(DB, primaryPool, cfg)
+	initTimeouts(cfg)
+	registerPoolMetrics(DB, metricsDBName)
+
+	// Database wajib tersedia, kegagalannya membuat layanan unhealthy
+	health.Register(health.Checker{
+		Name:     "database",
+		Check:    checkHealth,
+		Critical: true,
+	})
 
 	// Test koneksi
 	err = DB.Ping()
 	if err != nil {
-		log.Fatal("Error connecting to database:", err)
+		logger.Fatal("Error connecting to database", "error", err)
 	}
 
-	log.Println("Successfully connected to database")
+	slog.Info("Successfully connected to database")
 
 	// Buat tabel jika belum ada
 	createTables()
+
+	// Replica baca opsional, kegagalannya tidak menghentikan startup
+	initReplicas(cfg)
 }
 
-// configureConnectionPool configures database connection pool settings
-func configureConnectionPool() {
+// configureConnectionPool configures the connection pool settings of db
+func configureConnectionPool(db *sql.DB, pool string, cfg config.DatabaseConfig) {
 	// Set maximum number of open connections
-	maxOpenConns := getEnvAsInt("DB_MAX_OPEN_CONNS", 25)
-	DB.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
 
 	// Set maximum number of idle connections
-	maxIdleConns := getEnvAsInt("DB_MAX_IDLE_CONNS", 5)
-	DB.SetMaxIdleConns(maxIdleConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
 
 	// Set maximum lifetime of connections
-	maxLifetime := getEnvAsInt("DB_MAX_LIFETIME_MINUTES", 5)
-	DB.SetConnMaxLifetime(time.Duration(maxLifetime) * time.Minute)
+	db.SetConnMaxLifetime(time.Duration(cfg.MaxLifetimeMinutes) * time.Minute)
 
 	// Set maximum idle time of connections
-	maxIdleTime := getEnvAsInt("DB_MAX_IDLE_TIME_MINUTES", 5)
-	DB.SetConnMaxIdleTime(time.Duration(maxIdleTime) * time.Minute)
+	db.SetConnMaxIdleTime(time.Duration(cfg.MaxIdleTimeMinutes) * time.Minute)
 
-	log.Printf("Database connection pool configured: MaxOpen=%d, MaxIdle=%d, MaxLifetime=%dm, MaxIdleTime=%dm",
-		maxOpenConns, maxIdleConns, maxLifetime, maxIdleTime)
+	slog.Info("Database connection pool configured",
+		"pool", pool,
+		"max_open", cfg.MaxOpenConns,
+		"max_idle", cfg.MaxIdleConns,
+		"max_lifetime_minutes", cfg.MaxLifetimeMinutes,
+		"max_idle_time_minutes", cfg.MaxIdleTimeMinutes,
+	)
 }
 
-// getEnvAsInt gets environment variable as integer with default value
-func getEnvAsInt(key string, defaultValue int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// Close closes the primary and replica connection pools
+func Close() error {
+	if DB == nil {
+		return nil
 	}
 
-	intValue, err := fmt.Sscanf(value, "%d", &defaultValue)
-	if err != nil {
-		log.Printf("Warning: Invalid value for %s, using default %d", key, defaultValue)
-		return defaultValue
+	errs := []error{DB.Close()}
+	for _, r := range replicas {
+		errs = append(errs, r.db.Close())
 	}
-
-	return intValue
+	return errors.Join(errs...)
 }
 
-// GetDBStats returns database connection pool statistics
+// GetDBStats returns the primary connection pool statistics, with those of
+// each replica under "replicas"
 func GetDBStats() map[string]interface{} {
-	stats := DB.Stats()
+	stats := poolStats(DB)
+	if len(replicas) > 0 {
+		replicaStats := make(map[string]interface{}, len(replicas))
+		for _, r := range replicas {
+			replicaStats[r.name] = r.stats()
+		}
+		stats["replicas"] = replicaStats
+	}
+	return stats
+}
+
+// poolStats returns the connection pool statistics of db
+func poolStats(db *sql.DB) map[string]interface{} {
+	stats := db.Stats()
 	return map[string]interface{}{
 		"max_open_connections": stats.MaxOpenConnections,
 		"open_connections":     stats.OpenConnections,
@@ -121,6 +131,14 @@ func GetDBStats() map[string]interface{} {
 	}
 }
 
+// checkHealth pings the database and returns connection pool statistics
+func checkHealth(ctx context.Context) (map[string]interface{}, error) {
+	if err := DB.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	return poolStats(DB), nil
+}
+
 func createTables() {
 	// Tabel jobs
 	createJobsTable := `
@@ -145,15 +163,106 @@ func createTables() {
 		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 
+	alterJobsTable := []string{
+		// Deskripsi lowongan (HTML/markdown yang sudah disanitasi)
+		"ALTER TABLE jobs ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT ''",
+
+		// Jenis pekerjaan (full_time, part_time, contract, internship)
+		"ALTER TABLE jobs ADD COLUMN IF NOT EXISTS employment_type VARCHAR(32) NOT NULL DEFAULT 'full_time'",
+
+		// Dokumen pencarian teks untuk parameter q dan urutan relevansi. Tag dan
+		// entity HTML deskripsi dibuang agar nama tag seperti strong tidak ikut dicari.
+		`ALTER TABLE jobs ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', position || ' ' || company || ' ' ||
+				regexp_replace(description, '<[^>]*>|&[#a-zA-Z0-9]+;', ' ', 'g'))) STORED`,
+	}
+
+	// Kolom status untuk pelacakan lamaran oleh kandidat
+	alterApplicationsTable := []string{
+		"ALTER TABLE applications ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'submitted'",
+		"ALTER TABLE applications ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
 	_, err := DB.Exec(createJobsTable)
 	if err != nil {
-		log.Fatal("Error creating jobs table:", err)
+		logger.Fatal("Error creating jobs table", "error", err)
 	}
 
 	_, err = DB.Exec(createApplicationsTable)
 	if err != nil {
-		log.Fatal("Error creating applications table:", err)
+		logger.Fatal("Error creating applications table", "error", err)
 	}
 
-	log.Println("Tables created successfully")
+	// Dokumen pencarian versi lama dibangun dari HTML mentah, jadi dibuat ulang
+	var searchExpression string
+	err = DB.QueryRow(`
+	SELECT generation_expression FROM information_schema.columns
+	WHERE table_schema = current_schema() AND table_name = 'jobs' AND column_name = 'search_vector'`).Scan(&searchExpression)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		logger.Fatal("Error reading jobs search vector", "error", err)
+	case !strings.Contains(searchExpression, "regexp_replace"):
+		if _, err := DB.Exec("ALTER TABLE jobs DROP COLUMN search_vector"); err != nil {
+			logger.Fatal("Error dropping outdated jobs search vector", "error", err)
+		}
+	}
+
+	for _, query := range alterJobsTable {
+		_, err = DB.Exec(query)
+		if err != nil {
+			logger.Fatal("Error altering jobs table", "error", err)
+		}
+	}
+
+	// Jumlah lamaran per lowongan untuk urutan "paling banyak dilamar". Lamaran
+	// yang sudah ada dihitung sekali saat kolom ditambahkan; setelah itu
+	// CreateApplication menjaga jumlahnya.
+	addColumnOnce("jobs", "applications_count",
+		"ALTER TABLE jobs ADD COLUMN IF NOT EXISTS applications_count INTEGER NOT NULL DEFAULT 0",
+		`UPDATE jobs SET applications_count = counts.total
+		FROM (SELECT job_id, COUNT(*) AS total FROM applications GROUP BY job_id) counts
+		WHERE jobs.id = counts.job_id`)
+
+	for _, query := range alterApplicationsTable {
+		_, err = DB.Exec(query)
+		if err != nil {
+			logger.Fatal("Error altering applications table", "error", err)
+		}
+	}
+
+	slog.Info("Tables created successfully")
+}
+
+// addColumnOnce adds a column with alter and fills it with backfill in one
+// transaction, only when the column does not exist yet
+func addColumnOnce(table, column, alter, backfill string) {
+	var exists bool
+	err := DB.QueryRow(`
+	SELECT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2)`,
+		table, column).Scan(&exists)
+	if err != nil {
+		logger.Fatal("Error checking column", "table", table, "column", column, "error", err)
+	}
+	if exists {
+		return
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		logger.Fatal("Error adding column", "table", table, "column", column, "error", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{alter, backfill} {
+		if _, err := tx.Exec(query); err != nil {
+			logger.Fatal("Error adding column", "table", table, "column", column, "error", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Fatal("Error adding column", "table", table, "column", column, "error", err)
+	}
+	slog.Info("Column added", "table", table, "column", column)
 }
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/lib/pq"
+)
+
+// IsUnavailable reports whether err means the server cannot be reached or is
+// refusing connections, as opposed to a failing statement
+func IsUnavailable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "admin_shutdown", "crash_shutdown", "cannot_connect_now", "too_many_connections":
+			return true
+		}
+		return pqErr.Code.Class() == "08" // connection_exception
+	}
+
+	return false
+}
+
+// isRecoveryConflict reports whether a replica canceled a query because it
+// conflicted with replaying changes from the primary
+func isRecoveryConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "serialization_failure"
+}
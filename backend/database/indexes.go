@@ -1,7 +1,7 @@
 package database
 
 import (
-	"log"
+	"log/slog"
 )
 
 // CreateIndexes creates database indexes for better performance
@@ -17,12 +17,28 @@ func CreateIndexes() {
 		// Index on created_at for sorting
 		"CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs(created_at DESC)",
 
+		// Index on created_at and id for cursor pagination, which seeks on both
+		"CREATE INDEX IF NOT EXISTS idx_jobs_created_at_id ON jobs(created_at DESC, id DESC)",
+
 		// Composite index for location and salary filtering
 		"CREATE INDEX IF NOT EXISTS idx_jobs_location_salary ON jobs(location, salary_min, salary_max)",
 
 		// Index on company for searching
 		"CREATE INDEX IF NOT EXISTS idx_jobs_company ON jobs(company)",
 
+		// Indexes for the sort keys of job listings. The ID breaks ties, and
+		// each index is read backwards for the opposite direction.
+		"CREATE INDEX IF NOT EXISTS idx_jobs_salary_min_id ON jobs(salary_min, id)",
+		"CREATE INDEX IF NOT EXISTS idx_jobs_salary_max_id ON jobs(salary_max, id)",
+		"CREATE INDEX IF NOT EXISTS idx_jobs_company_id ON jobs(company, id)",
+		"CREATE INDEX IF NOT EXISTS idx_jobs_applications_count_id ON jobs(applications_count, id)",
+
+		// Index on employment type for filtering
+		"CREATE INDEX IF NOT EXISTS idx_jobs_employment_type ON jobs(employment_type)",
+
+		// Full-text index for the search query
+		"CREATE INDEX IF NOT EXISTS idx_jobs_search ON jobs USING GIN (search_vector)",
+
 		// Index on position for searching
 		"CREATE INDEX IF NOT EXISTS idx_jobs_position ON jobs(position)",
 	}
@@ -38,6 +54,9 @@ func CreateIndexes() {
 		// Index on email for searching
 		"CREATE INDEX IF NOT EXISTS idx_applications_email ON applications(email)",
 
+		// Index on normalized email for candidate self-service lookups
+		"CREATE INDEX IF NOT EXISTS idx_applications_email_lower ON applications(LOWER(email))",
+
 		// Composite index for job_id and applied_at
 		"CREATE INDEX IF NOT EXISTS idx_applications_job_applied ON applications(job_id, applied_at DESC)",
 	}
@@ -46,9 +65,9 @@ func CreateIndexes() {
 	for _, query := range createJobIndexes {
 		_, err := DB.Exec(query)
 		if err != nil {
-			log.Printf("Error creating job index: %v", err)
+			slog.Error("Error creating job index", "error", err)
 		} else {
-			log.Printf("Job index created successfully")
+			slog.Debug("Job index created successfully")
 		}
 	}
 
@@ -56,13 +75,13 @@ func CreateIndexes() {
 	for _, query := range createApplicationIndexes {
 		_, err := DB.Exec(query)
 		if err != nil {
-			log.Printf("Error creating application index: %v", err)
+			slog.Error("Error creating application index", "error", err)
 		} else {
-			log.Printf("Application index created successfully")
+			slog.Debug("Application index created successfully")
 		}
 	}
 
-	log.Println("Database indexes created successfully")
+	slog.Info("Database indexes created successfully")
 }
 
 // AnalyzeTables runs ANALYZE on tables for query optimization
@@ -73,9 +92,9 @@ func AnalyzeTables() {
 		query := "ANALYZE " + table
 		_, err := DB.Exec(query)
 		if err != nil {
-			log.Printf("Error analyzing table %s: %v", table, err)
+			slog.Error("Error analyzing table", "table", table, "error", err)
 		} else {
-			log.Printf("Table %s analyzed successfully", table)
+			slog.Info("Table analyzed successfully", "table", table)
 		}
 	}
 }
@@ -88,9 +107,9 @@ func VacuumTables() {
 		query := "VACUUM " + table
 		_, err := DB.Exec(query)
 		if err != nil {
-			log.Printf("Error vacuuming table %s: %v", table, err)
+			slog.Error("Error vacuuming table", "table", table, "error", err)
 		} else {
-			log.Printf("Table %s vacuumed successfully", table)
+			slog.Info("Table vacuumed successfully", "table", table)
 		}
 	}
 }
@@ -103,7 +122,7 @@ func GetTableStats() map[string]interface{} {
 	var jobsCount int
 	err := DB.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&jobsCount)
 	if err != nil {
-		log.Printf("Error getting jobs count: %v", err)
+		slog.Error("Error getting jobs count", "error", err)
 	} else {
 		stats["jobs_count"] = jobsCount
 	}
@@ -112,7 +131,7 @@ func GetTableStats() map[string]interface{} {
 	var applicationsCount int
 	err = DB.QueryRow("SELECT COUNT(*) FROM applications").Scan(&applicationsCount)
 	if err != nil {
-		log.Printf("Error getting applications count: %v", err)
+		slog.Error("Error getting applications count", "error", err)
 	} else {
 		stats["applications_count"] = applicationsCount
 	}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// metricsDBName labels the connection pool metrics
+const metricsDBName = "job_portal"
+
+var dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "db_query_duration_seconds",
+	Help:    "Database query latency by query name.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"query"})
+
+var dbQueryTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "db_query_timeouts_total",
+	Help: "Database queries that ran past their deadline by query name.",
+}, []string{"query"})
+
+// registerPoolMetrics exposes the sql.DBStats of db as go_sql_* metrics
+// labeled with dbName
+func registerPoolMetrics(db *sql.DB, dbName string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
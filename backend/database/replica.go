@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"job-portal-backend/config"
+	"job-portal-backend/health"
+	"job-portal-backend/logger"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// primaryPool names the primary in logs, metrics and traces; replicas are
+// named replica_1, replica_2 and so on
+const primaryPool = "primary"
+
+// replicaRetryAfter is how long reads skip a replica that could not be reached
+const replicaRetryAfter = 10 * time.Second
+
+// replicationLagQuery reports how far a replica is behind the primary, NULL
+// on a primary
+const replicationLagQuery = "SELECT EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())"
+
+// replica is a read-only connection pool
+type replica struct {
+	name string
+	db   *sql.DB
+
+	// downUntil is when a failed replica is tried again, in Unix nanoseconds
+	downUntil atomic.Int64
+}
+
+var (
+	replicas    []*replica
+	nextReplica atomic.Uint64
+
+	dbReads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_read_queries_total",
+		Help: "Read queries by connection pool (primary or replica name).",
+	}, []string{"pool"})
+
+	dbReplicaFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_replica_fallbacks_total",
+		Help: "Read queries retried on the primary after a replica failed, by replica.",
+	}, []string{"replica"})
+)
+
+// primaryContextKey marks contexts whose reads must run on the primary
+type primaryContextKey struct{}
+
+// initReplicas opens the replica pools of cfg. A replica that cannot be
+// reached at startup is skipped until its health check succeeds.
+func initReplicas(cfg config.DatabaseConfig) {
+	for i, url := range cfg.ReplicaURLs {
+		name := fmt.Sprintf("replica_%d", i+1)
+
+		db, err := sql.Open("postgres", url)
+		if err != nil {
+			logger.Fatal("Error opening database replica", "replica", name, "error", err)
+		}
+		configureConnectionPool(db, name, cfg)
+		registerPoolMetrics(db, metricsDBName+"_"+name)
+
+		r := &replica{name: name, db: db}
+		replicas = append(replicas, r)
+
+		// Reads fall back to the primary, so a replica only degrades the service
+		health.Register(health.Checker{
+			Name:  "database_" + name,
+			Check: r.checkHealth,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), health.DefaultTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err != nil {
+			r.markDown()
+			slog.Warn("Database replica unavailable, reading from primary", "replica", name, "error", err)
+			continue
+		}
+		slog.Info("Successfully connected to database replica", "replica", name)
+	}
+}
+
+// HasReplicas reports whether read replicas are configured
+func HasReplicas() bool {
+	return len(replicas) > 0
+}
+
+// WithPrimary returns a context whose reads run on the primary, used right
+// after a client's own writes so it does not see replication lag
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// Read runs the read-only query fn on a replica, or on the primary when there
+// are no replicas, all are down or ctx was marked WithPrimary. When the replica
+// cannot be reached or cancels the query because of replication, fn runs again
+// on the primary, so it must not keep results from a failed attempt:
+//
+//	err = database.Read(ctx, func(db *sql.DB) error {
+//		return db.QueryRowContext(ctx, query, id).Scan(&job.ID, ...)
+//	})
+func Read(ctx context.Context, fn func(db *sql.DB) error) error {
+	r := pickReplica(ctx)
+	if r == nil {
+		return readOn(ctx, DB, primaryPool, fn)
+	}
+
+	err := readOn(ctx, r.db, r.name, fn)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	unavailable := IsUnavailable(err)
+	if !unavailable && !isRecoveryConflict(err) {
+		return err
+	}
+	if unavailable {
+		r.markDown()
+	}
+
+	dbReplicaFallbacks.WithLabelValues(r.name).Inc()
+	slog.WarnContext(ctx, "Database replica read failed, retrying on primary", "replica", r.name, "error", err)
+	return readOn(ctx, DB, primaryPool, fn)
+}
+
+// readOn runs fn on db, recording the pool on the query span
+func readOn(ctx context.Context, db *sql.DB, pool string, fn func(db *sql.DB) error) error {
+	dbReads.WithLabelValues(pool).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.pool", pool))
+	return fn(db)
+}
+
+// pickReplica returns the next available replica in turn, or nil when reads
+// must use the primary
+func pickReplica(ctx context.Context) *replica {
+	if len(replicas) == 0 || ctx.Value(primaryContextKey{}) != nil {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+	start := nextReplica.Add(1)
+	for i := range uint64(len(replicas)) {
+		r := replicas[(start+i)%uint64(len(replicas))]
+		if r.downUntil.Load() <= now {
+			return r
+		}
+	}
+	return nil
+}
+
+// markDown skips the replica for replicaRetryAfter
+func (r *replica) markDown() {
+	r.downUntil.Store(time.Now().Add(replicaRetryAfter).UnixNano())
+}
+
+// available reports whether reads use the replica
+func (r *replica) available() bool {
+	return r.downUntil.Load() <= time.Now().UnixNano()
+}
+
+// stats returns the connection pool statistics of the replica
+func (r *replica) stats() map[string]interface{} {
+	stats := poolStats(r.db)
+	stats["available"] = r.available()
+	return stats
+}
+
+// checkHealth pings the replica, putting it back in rotation when it answers,
+// and reports its replication lag with its pool statistics
+func (r *replica) checkHealth(ctx context.Context) (map[string]interface{}, error) {
+	if err := r.db.PingContext(ctx); err != nil {
+		r.markDown()
+		return nil, err
+	}
+	r.downUntil.Store(0)
+
+	stats := r.stats()
+	var lag sql.NullFloat64
+	if err := r.db.QueryRowContext(ctx, replicationLagQuery).Scan(&lag); err == nil && lag.Valid {
+		stats["replication_lag"] = time.Duration(lag.Float64 * float64(time.Second)).Round(time.Millisecond).String()
+	}
+	return stats, nil
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"job-portal-backend/config"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// readTimeout and writeTimeout bound SELECT queries and other statements
+	readTimeout  = config.Default().Database.ReadTimeout
+	writeTimeout = config.Default().Database.WriteTimeout
+
+	// queryTimeouts overrides the deadline of queries by name
+	queryTimeouts map[string]time.Duration
+)
+
+// modifyingStatement finds the data-modifying statements a WITH query may
+// hold, which make it a write
+var modifyingStatement = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE)\b`)
+
+// initTimeouts applies the query deadlines of cfg
+func initTimeouts(cfg config.DatabaseConfig) {
+	readTimeout = cfg.ReadTimeout
+	writeTimeout = cfg.WriteTimeout
+	queryTimeouts = cfg.QueryTimeouts
+}
+
+// queryTimeout returns the deadline of the query name running statement
+func queryTimeout(name, statement string) time.Duration {
+	if timeout, ok := queryTimeouts[name]; ok {
+		return timeout
+	}
+
+	verb, _, _ := strings.Cut(strings.TrimSpace(statement), " ")
+	switch {
+	case strings.EqualFold(verb, "SELECT"):
+		return readTimeout
+	case strings.EqualFold(verb, "WITH") && !modifyingStatement.MatchString(statement):
+		return readTimeout
+	}
+	return writeTimeout
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"job-portal-backend/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartQuery starts a span for a query and returns the context to run it with,
+// bounded by the query deadline, and a function that ends the span, releases
+// the deadline and records the duration. Use it at the top of a model function:
+//
+//	ctx, end := database.StartQuery(ctx, "get_job_by_id", query)
+//	defer func() { end(err) }()
+//
+// sql.ErrNoRows is not recorded as an error.
+func StartQuery(ctx context.Context, name, statement string) (context.Context, func(error)) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout(name, statement))
+	ctx, span := tracing.Tracer().Start(ctx, "db "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(statement),
+		),
+	)
+
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			span.SetAttributes(attribute.Bool("db.timeout", true))
+			dbQueryTimeouts.WithLabelValues(name).Inc()
+		}
+		span.End()
+		cancel()
+		dbQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	}
+}
@@ -24,8 +24,13 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/applications": {
+        "/admin/applications": {
             "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
                 "description": "Retrieve a list of all job applications",
                 "consumes": [
                     "application/json"
@@ -34,7 +39,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "applications"
+                    "admin"
                 ],
                 "summary": "Get all applications",
                 "responses": {
@@ -47,84 +52,40 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Missing or invalid admin API key",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Submit a job application with CV file upload",
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "applications"
-                ],
-                "summary": "Submit a job application",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Applicant name",
-                        "name": "name",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Applicant email",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Job ID",
-                        "name": "job_id",
-                        "in": "formData",
-                        "required": true
                     },
-                    {
-                        "type": "file",
-                        "description": "CV file (PDF only, max 5MB)",
-                        "name": "cv",
-                        "in": "formData",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Application submitted successfully",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request data",
+                    "503": {
+                        "description": "Database or cache unavailable",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "504": {
+                        "description": "Operation timed out",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
             }
         },
-        "/applications/{id}": {
+        "/admin/applications/{id}": {
             "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
                 "description": "Retrieve a specific application by its ID",
                 "consumes": [
                     "application/json"
@@ -133,7 +94,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "applications"
+                    "admin"
                 ],
                 "summary": "Get application by ID",
                 "parameters": [
@@ -155,153 +116,179 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid application ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin API key",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
                     "404": {
                         "description": "Application not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
             }
         },
-        "/jobs": {
+        "/admin/cache": {
             "get": {
-                "description": "Retrieve a list of jobs with optional filtering and pagination",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "Cache mode, number of local entries and hit ratio per namespace since startup",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
-                ],
-                "summary": "Get all jobs with pagination and filters",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page (default: 12, max: 50)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by location",
-                        "name": "location",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Minimum salary filter",
-                        "name": "salary_min",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum salary filter",
-                        "name": "salary_max",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "Get cache statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.PaginatedResponse"
+                            "$ref": "#/definitions/cache.Stats"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Missing or invalid admin API key",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new job posting",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/cache/keys": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "List the keys of a cache namespace with their remaining TTL, per tier",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "admin"
                 ],
-                "summary": "Create a new job",
+                "summary": "List cached keys",
                 "parameters": [
                     {
-                        "description": "Job object",
-                        "name": "job",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Job"
-                        }
+                        "enum": [
+                            "jobs",
+                            "job",
+                            "locations",
+                            "applications",
+                            "application"
+                        ],
+                        "type": "string",
+                        "description": "Cache namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of keys per tier",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Job"
+                            "$ref": "#/definitions/handlers.CacheKeysResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Unknown namespace or invalid limit",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin API key",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Cache operation failed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
             }
         },
-        "/jobs/{id}": {
-            "get": {
-                "description": "Retrieve a specific job by its ID",
-                "consumes": [
-                    "application/json"
+        "/admin/cache/namespaces/{namespace}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "Remove every key of a cache namespace on every instance, leaving other data in Redis untouched",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "admin"
                 ],
-                "summary": "Get a job by ID",
+                "summary": "Evict a cache namespace",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Job ID",
-                        "name": "id",
+                        "enum": [
+                            "jobs",
+                            "job",
+                            "locations",
+                            "applications",
+                            "application"
+                        ],
+                        "type": "string",
+                        "description": "Cache namespace",
+                        "name": "namespace",
                         "in": "path",
                         "required": true
                     }
@@ -310,95 +297,1078 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Job"
+                            "$ref": "#/definitions/handlers.CacheEvictResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid job ID",
+                        "description": "Unknown namespace",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Job not found",
+                    "401": {
+                        "description": "Missing or invalid admin API key",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Cache operation failed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
             }
         },
-        "/locations": {
-            "get": {
-                "description": "Retrieve a list of all unique locations where jobs are available",
-                "consumes": [
-                    "application/json"
+        "/admin/cache/warm": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "Load the locations, the first listing pages and the most applied jobs into the cache",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "admin"
                 ],
-                "summary": "Get all available locations",
+                "summary": "Warm up the cache",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.WarmUpResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin API key",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Cache operation failed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.PaginatedResponse": {
-            "type": "object",
-            "properties": {
-                "jobs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Job"
-                    }
-                },
-                "pagination": {
-                    "type": "object",
-                    "properties": {
-                        "has_next": {
-                            "type": "boolean"
-                        },
-                        "has_prev": {
-                            "type": "boolean"
-                        },
-                        "limit": {
-                            "type": "integer"
-                        },
+        },
+        "/applications": {
+            "post": {
+                "description": "Submit a job application with CV file upload",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Submit a job application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Applicant name",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Applicant email",
+                        "name": "email",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "job_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CV file (PDF only, max 5MB)",
+                        "name": "cv",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Application submitted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "CV upload too large",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/applications/magic-link": {
+            "post": {
+                "description": "Email a link that lets the candidate track their applications without a password",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "candidate"
+                ],
+                "summary": "Request a magic link",
+                "parameters": [
+                    {
+                        "description": "Email used when applying",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MagicLinkInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Magic link sent if applications exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/candidate/applications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the applications of the candidate identified by the magic link",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "candidate"
+                ],
+                "summary": "Get my applications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Magic link token (alternative to the Authorization header)",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Application"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired magic link",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/candidate/applications/{id}/cv": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a new CV for one of the candidate's own applications",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "candidate"
+                ],
+                "summary": "Replace application CV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Magic link token (alternative to the Authorization header)",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "CV file (PDF only, max 5MB)",
+                        "name": "cv",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Application"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired magic link",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Application not found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Application already withdrawn",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "CV upload too large",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/candidate/applications/{id}/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Withdraw one of the candidate's own applications",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "candidate"
+                ],
+                "summary": "Withdraw an application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Magic link token (alternative to the Authorization header)",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Application"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid application ID",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired magic link",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Application not found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Application already withdrawn",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Runs every registered dependency check. Returns 200 when healthy or degraded (an optional dependency such as the cache is down) and 503 when a critical dependency is down",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Simple liveness check for Kubernetes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Readiness check for Kubernetes, not ready when a critical dependency is down or the server is shutting down",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "Retrieve a list of jobs, newest first unless sorted, with optional filtering and text search. Pages are selected by page number or by the opaque cursors returned in the pagination, which stay stable while jobs are posted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get all jobs with pagination and filters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1), cannot be combined with cursor",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 12, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor or prev_cursor of a previous response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "estimate",
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "How to count the total: exact, estimate or none (default: exact with page, none with cursor)",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search the position, company and description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys (created_at, salary_min, salary_max, company, applications, relevance), each optionally followed by :asc or :desc (default: created_at:desc). relevance requires q. A cursor keeps the sort it was returned with.",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by location",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum salary filter",
+                        "name": "salary_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum salary filter",
+                        "name": "salary_max",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "full_time",
+                            "part_time",
+                            "contract",
+                            "internship"
+                        ],
+                        "type": "string",
+                        "description": "Filter by employment type",
+                        "name": "employment_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by company",
+                        "name": "company",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated facets to count for the filters (location, salary, employment_type, company)",
+                        "name": "facets",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new job posting",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Create a new job",
+                "parameters": [
+                    {
+                        "description": "Job object",
+                        "name": "job",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.JobInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Retrieve a specific job by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get a job by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid job ID",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/locations": {
+            "get": {
+                "description": "Retrieve a list of all unique locations where jobs are available",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get all available locations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database or cache unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Operation timed out",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Prometheus metrics in the text exposition format, or the JSON view with format=json",
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Metrics endpoint",
+                "parameters": [
+                    {
+                        "enum": [
+                            "prometheus",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Prometheus text exposition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "cache.KeyInfo": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "job:42"
+                },
+                "tier": {
+                    "type": "string",
+                    "example": "redis"
+                },
+                "ttl_seconds": {
+                    "description": "TTLSeconds is the time left before the key expires, -1 without expiration",
+                    "type": "integer",
+                    "example": 540
+                }
+            }
+        },
+        "cache.NamespaceStats": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "hit_ratio": {
+                    "type": "number",
+                    "example": 0.95
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 950
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "cache.Stats": {
+            "type": "object",
+            "properties": {
+                "local_entries": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "tiered"
+                },
+                "namespaces": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/cache.NamespaceStats"
+                    }
+                }
+            }
+        },
+        "handlers.CacheEvictResponse": {
+            "type": "object",
+            "properties": {
+                "namespace": {
+                    "type": "string",
+                    "example": "job"
+                },
+                "removed": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "handlers.CacheKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/cache.KeyInfo"
+                    }
+                },
+                "namespace": {
+                    "type": "string",
+                    "example": "job"
+                }
+            }
+        },
+        "handlers.DiskUsage": {
+            "type": "object",
+            "properties": {
+                "free": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "degraded"
+                },
+                "system": {
+                    "$ref": "#/definitions/handlers.SystemHealth"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "uptime": {
+                    "type": "string",
+                    "example": "1h2m3s"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
+                }
+            }
+        },
+        "handlers.MemoryUsage": {
+            "type": "object",
+            "properties": {
+                "alloc": {
+                    "type": "integer"
+                },
+                "heap_alloc": {
+                    "type": "integer"
+                },
+                "heap_sys": {
+                    "type": "integer"
+                },
+                "num_gc": {
+                    "type": "integer"
+                },
+                "sys": {
+                    "type": "integer"
+                },
+                "total_alloc": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "facets": {
+                    "description": "Facets is set when facets are requested",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.JobFacets"
+                        }
+                    ]
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Job"
+                    }
+                },
+                "pagination": {
+                    "type": "object",
+                    "properties": {
+                        "has_next": {
+                            "type": "boolean"
+                        },
+                        "has_prev": {
+                            "type": "boolean"
+                        },
+                        "limit": {
+                            "type": "integer"
+                        },
+                        "next_cursor": {
+                            "type": "string"
+                        },
                         "page": {
+                            "description": "Page is set for listings selected by page number",
                             "type": "integer"
                         },
+                        "prev_cursor": {
+                            "type": "string"
+                        },
                         "total": {
+                            "description": "Total and TotalPages are left out when counting is skipped.\nTotalEstimated reports a count estimated by the query planner.",
                             "type": "integer"
                         },
+                        "total_estimated": {
+                            "type": "boolean"
+                        },
                         "total_pages": {
                             "type": "integer"
                         }
@@ -406,6 +1376,149 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.SystemHealth": {
+            "type": "object",
+            "properties": {
+                "cpu_usage": {
+                    "type": "number"
+                },
+                "disk_usage": {
+                    "$ref": "#/definitions/handlers.DiskUsage"
+                },
+                "goroutines": {
+                    "type": "integer"
+                },
+                "memory_usage": {
+                    "$ref": "#/definitions/handlers.MemoryUsage"
+                }
+            }
+        },
+        "handlers.WarmUpResult": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "example": "215ms"
+                },
+                "jobs": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "listing_pages": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "locations": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "health.Result": {
+            "description": "Outcome of a dependency check",
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency": {
+                    "type": "string",
+                    "example": "1.2ms"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
+                }
+            }
+        },
+        "middleware.Problem": {
+            "description": "RFC 7807 problem details, with compatibility members unless ERROR_FORMAT=problem",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "JOB_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Lowongan tidak ditemukan"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.ValidationError"
+                    }
+                },
+                "error": {
+                    "description": "Compatibility members for clients written against the previous error shape",
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.ValidationError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/jobs/42"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Lowongan tidak ditemukan"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "20250115103000-abcd1234"
+                },
+                "retry_after": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2025-01-15T10:30:00Z"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:job-portal:problem:job-not-found"
+                }
+            }
+        },
+        "middleware.ValidationError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Application": {
             "description": "Job application information",
             "type": "object",
@@ -416,7 +1529,7 @@ const docTemplate = `{
                 },
                 "cv_filename": {
                     "type": "string",
-                    "example": "cv_1234567890.pdf"
+                    "example": "cv_wcpswhoinpfplsxqugz3l4akf2.pdf"
                 },
                 "email": {
                     "type": "string",
@@ -436,6 +1549,37 @@ const docTemplate = `{
                 "name": {
                     "type": "string",
                     "example": "John Doe"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "submitted"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:30:00Z"
+                }
+            }
+        },
+        "models.FacetValue": {
+            "description": "Number of jobs matching a filter value",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "max": {
+                    "type": "integer",
+                    "example": 5000000
+                },
+                "min": {
+                    "description": "Min and Max are the filter bounds of a salary bucket",
+                    "type": "integer",
+                    "example": 3000000
+                },
+                "value": {
+                    "type": "string",
+                    "example": "Jakarta"
                 }
             }
         },
@@ -443,6 +1587,11 @@ const docTemplate = `{
             "description": "Job posting information",
             "type": "object",
             "properties": {
+                "applications_count": {
+                    "description": "ApplicationsCount is the number of applications submitted for the job",
+                    "type": "integer",
+                    "example": 12
+                },
                 "company": {
                     "type": "string",
                     "example": "TechCorp Indonesia"
@@ -451,6 +1600,20 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-01-15T10:30:00Z"
                 },
+                "description": {
+                    "type": "string",
+                    "example": "\u003cp\u003eBuild \u003cstrong\u003eresponsive\u003c/strong\u003e interfaces with React.\u003c/p\u003e"
+                },
+                "employment_type": {
+                    "type": "string",
+                    "enum": [
+                        "full_time",
+                        "part_time",
+                        "contract",
+                        "internship"
+                    ],
+                    "example": "full_time"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -463,18 +1626,109 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Frontend Developer"
                 },
+                "relevance": {
+                    "description": "Relevance ranks the job against the search query of a listing",
+                    "type": "number",
+                    "example": 0.0608
+                },
+                "salary_max": {
+                    "type": "integer",
+                    "example": 5000000
+                },
+                "salary_min": {
+                    "type": "integer",
+                    "example": 3000000
+                }
+            }
+        },
+        "models.JobFacets": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "array",
+                "items": {
+                    "$ref": "#/definitions/models.FacetValue"
+                }
+            }
+        },
+        "models.JobInput": {
+            "description": "Job creation payload, accepted as JSON or form data",
+            "type": "object",
+            "required": [
+                "company",
+                "location",
+                "position",
+                "salary_max",
+                "salary_min"
+            ],
+            "properties": {
+                "company": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "TechCorp Indonesia"
+                },
+                "description": {
+                    "description": "Description allows basic HTML and markdown; anything else is stripped",
+                    "type": "string",
+                    "maxLength": 5000,
+                    "example": "\u003cp\u003eBuild \u003cstrong\u003eresponsive\u003c/strong\u003e interfaces with React.\u003c/p\u003e"
+                },
+                "employment_type": {
+                    "description": "EmploymentType defaults to full_time",
+                    "type": "string",
+                    "enum": [
+                        "full_time",
+                        "part_time",
+                        "contract",
+                        "internship"
+                    ],
+                    "example": "full_time"
+                },
+                "location": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2,
+                    "example": "Jakarta"
+                },
+                "position": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "Frontend Developer"
+                },
                 "salary_max": {
                     "type": "integer",
+                    "minimum": 1,
                     "example": 5000000
                 },
                 "salary_min": {
                     "type": "integer",
+                    "minimum": 1,
                     "example": 3000000
                 }
             }
+        },
+        "models.MagicLinkInput": {
+            "description": "Magic link request payload",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "AdminKey": {
+            "description": "Admin API key for the /admin endpoints.",
+            "type": "apiKey",
+            "name": "X-Admin-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
@@ -1,20 +1,29 @@
 package handlers
 
 import (
+	"crypto/rand"
 	"fmt"
+	"job-portal-backend/cache"
+	"job-portal-backend/i18n"
 	"job-portal-backend/models"
-	"mime/multipart"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"job-portal-backend/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+var applicationsSubmitted = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "applications_submitted_total",
+	Help: "Job applications submitted successfully.",
+})
+
 // CreateApplication godoc
 // @Summary Submit a job application
 // @Description Submit a job application with CV file upload
@@ -26,79 +35,58 @@ import (
 // @Param job_id formData int true "Job ID"
 // @Param cv formData file true "CV file (PDF only, max 5MB)"
 // @Success 201 {object} map[string]interface{} "Application submitted successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request data"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 400 {object} middleware.Problem "Invalid request data"
+// @Failure 413 {object} middleware.Problem "CV upload too large"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
 // @Router /applications [post]
 func CreateApplication(c *gin.Context) {
-	// Parse form data
-	name := c.PostForm("name")
-	email := c.PostForm("email")
-	jobIDStr := c.PostForm("job_id")
-
-	// Validasi input
-	if name == "" || email == "" || jobIDStr == "" {
-		middleware.CustomError(c, http.StatusBadRequest, "Validation Error", "Name, email, and job_id are required")
-		return
-	}
+	input := c.MustGet(middleware.ApplicationInputKey).(models.ApplicationInput)
 
-	// Parse job ID
-	jobID, err := strconv.Atoi(jobIDStr)
-	if err != nil {
-		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", "Invalid job ID")
-		return
-	}
-
-	// Validasi email format sederhana
-	if !strings.Contains(email, "@") {
-		middleware.CustomError(c, http.StatusBadRequest, "Validation Error", "Invalid email format")
-		return
-	}
-
-	// Handle file upload
+	// Handle file upload (type and size are checked by ValidateApplicationInput)
 	file, err := c.FormFile("cv")
 	if err != nil {
-		middleware.CustomError(c, http.StatusBadRequest, "File Error", "CV file is required")
-		return
-	}
-
-	// Validasi file type
-	if !isValidPDF(file) {
-		middleware.CustomError(c, http.StatusBadRequest, "File Error", "Only PDF files are allowed")
-		return
-	}
-
-	// Validasi file size (max 5MB)
-	if file.Size > 5*1024*1024 {
-		middleware.CustomError(c, http.StatusBadRequest, "File Error", "File size must be less than 5MB")
+		middleware.CustomError(c, http.StatusBadRequest, "File Error", i18n.ErrCVRequired)
 		return
 	}
 
-	// Generate unique filename
+	// Generate a random filename
 	filename := generateUniqueFilename(file.Filename)
 
 	// Save file - Use /tmp for Railway deployment
 	uploadPath := fmt.Sprintf("/tmp/%s", filename)
 	if err := c.SaveUploadedFile(file, uploadPath); err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "File Error", "Failed to save file")
+		middleware.CustomError(c, http.StatusInternalServerError, "File Error", i18n.ErrCVSaveFailed)
 		return
 	}
 
 	// Create application
 	application := &models.Application{
-		JobID:      jobID,
-		Name:       name,
-		Email:      email,
+		JobID:      input.JobID,
+		Name:       input.Name,
+		Email:      input.Email,
 		CVFilename: filename,
 	}
 
-	err = models.CreateApplication(application)
+	err = models.CreateApplication(c.Request.Context(), application)
 	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to create application")
+		os.Remove(uploadPath)
+		middleware.OperationError(c, err, "Database Error", i18n.ErrCreateApplicationFailed)
 		return
 	}
 
+	applicationsSubmitted.Inc()
+
+	// The job and its listings show the application count, which just changed
+	cache.InvalidateJobCache(c.Request.Context(), input.JobID)
+	cache.InvalidateJobsCache(c.Request.Context())
+
+	// Email the candidate a link to track this and their other applications
+	sendMagicLink(input.Email, input.Name)
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message":     "Application submitted successfully",
+		"message":     middleware.Localize(c, i18n.MsgApplicationSubmitted),
 		"application": application,
 	})
 }
@@ -106,16 +94,20 @@ func CreateApplication(c *gin.Context) {
 // GetApplications godoc
 // @Summary Get all applications
 // @Description Retrieve a list of all job applications
-// @Tags applications
+// @Tags admin
 // @Accept json
 // @Produce json
+// @Security AdminKey
 // @Success 200 {array} models.Application
-// @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /applications [get]
+// @Failure 401 {object} middleware.Problem "Missing or invalid admin API key"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /admin/applications [get]
 func GetApplications(c *gin.Context) {
-	applications, err := models.GetApplications()
+	applications, err := models.GetApplications(c.Request.Context())
 	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to fetch applications")
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchApplicationsFailed)
 		return
 	}
 
@@ -125,31 +117,35 @@ func GetApplications(c *gin.Context) {
 // GetApplicationByID godoc
 // @Summary Get application by ID
 // @Description Retrieve a specific application by its ID
-// @Tags applications
+// @Tags admin
 // @Accept json
 // @Produce json
+// @Security AdminKey
 // @Param id path int true "Application ID"
 // @Success 200 {object} models.Application
-// @Failure 400 {object} map[string]interface{} "Invalid application ID"
-// @Failure 404 {object} map[string]interface{} "Application not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /applications/{id} [get]
+// @Failure 400 {object} middleware.Problem "Invalid application ID"
+// @Failure 401 {object} middleware.Problem "Missing or invalid admin API key"
+// @Failure 404 {object} middleware.Problem "Application not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /admin/applications/{id} [get]
 func GetApplicationByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", "Invalid application ID")
+		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", i18n.ErrInvalidApplicationID)
 		return
 	}
 
-	application, err := models.GetApplicationByID(id)
+	application, err := models.GetApplicationByID(c.Request.Context(), id)
 	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to fetch application")
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchApplicationFailed)
 		return
 	}
 
 	if application == nil {
-		middleware.CustomError(c, http.StatusNotFound, "Not Found", "Application not found")
+		middleware.CustomError(c, http.StatusNotFound, "Not Found", i18n.ErrApplicationNotFound)
 		return
 	}
 
@@ -157,13 +153,10 @@ func GetApplicationByID(c *gin.Context) {
 }
 
 // Helper functions
-func isValidPDF(file *multipart.FileHeader) bool {
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	return ext == ".pdf"
-}
 
+// generateUniqueFilename returns a random CV filename, so that concurrent
+// uploads never share a file and replaced CVs can be removed by name
 func generateUniqueFilename(originalName string) string {
 	ext := filepath.Ext(originalName)
-	timestamp := time.Now().Unix()
-	return fmt.Sprintf("cv_%d%s", timestamp, ext)
+	return fmt.Sprintf("cv_%s%s", strings.ToLower(rand.Text()), ext)
 }
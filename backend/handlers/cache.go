@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal-backend/cache"
+	"job-portal-backend/i18n"
+	"job-portal-backend/middleware"
+	"job-portal-backend/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache key listing limits
+const (
+	defaultCacheKeysLimit = 100
+	maxCacheKeysLimit     = 1000
+)
+
+// WarmUpResult reports what a cache warm-up loaded
+type WarmUpResult struct {
+	Locations    bool   `json:"locations" example:"true"`
+	ListingPages int    `json:"listing_pages" example:"3"`
+	Jobs         int    `json:"jobs" example:"20"`
+	Duration     string `json:"duration" example:"215ms"`
+}
+
+// CacheKeysResponse lists the cached keys of a namespace
+type CacheKeysResponse struct {
+	Namespace string          `json:"namespace" example:"job"`
+	Keys      []cache.KeyInfo `json:"keys"`
+}
+
+// CacheEvictResponse reports the eviction of a namespace
+type CacheEvictResponse struct {
+	Namespace string `json:"namespace" example:"job"`
+	Removed   int    `json:"removed" example:"42"`
+}
+
+// WarmCache loads the locations, the first listing pages and the most
+// applied jobs into the cache, so the first users after a deploy or a cache
+// flush do not all hit the database. Entries already cached are kept. It
+// carries on after a failure and returns every error it met.
+func WarmCache(ctx context.Context) (WarmUpResult, error) {
+	start := time.Now()
+	var result WarmUpResult
+	var errs []error
+
+	if _, err := fetchLocations(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("locations: %w", err))
+	} else {
+		result.Locations = true
+	}
+
+	for page := 1; page <= warmUpPages; page++ {
+		// Match the listings requested without parameters
+		listing := jobListing{Sort: models.DefaultJobSort, Page: page, Limit: defaultJobsLimit, Count: models.CountExact}
+		response, err := fetchJobListing(ctx, listing)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing page %d: %w", page, err))
+			break
+		}
+		result.ListingPages++
+		if !response.Pagination.HasNext {
+			break
+		}
+	}
+
+	if warmUpJobs > 0 {
+		ids, err := models.GetPopularJobIDs(ctx, warmUpJobs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("popular jobs: %w", err))
+		}
+		for _, id := range ids {
+			if _, err := fetchJob(ctx, id); err != nil && !errors.Is(err, errJobNotFound) {
+				errs = append(errs, fmt.Errorf("job %d: %w", id, err))
+				continue
+			}
+			result.Jobs++
+		}
+	}
+
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	err := errors.Join(errs...)
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "Cache warm-up finished",
+		"locations", result.Locations,
+		"listing_pages", result.ListingPages,
+		"jobs", result.Jobs,
+		"duration", result.Duration,
+		"error", err,
+	)
+
+	return result, err
+}
+
+// GetCacheStats godoc
+// @Summary Get cache statistics
+// @Description Cache mode, number of local entries and hit ratio per namespace since startup
+// @Tags admin
+// @Produce json
+// @Security AdminKey
+// @Success 200 {object} cache.Stats
+// @Failure 401 {object} middleware.Problem "Missing or invalid admin API key"
+// @Router /admin/cache [get]
+func GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, cache.GetCacheStats())
+}
+
+// GetCacheKeys godoc
+// @Summary List cached keys
+// @Description List the keys of a cache namespace with their remaining TTL, per tier
+// @Tags admin
+// @Produce json
+// @Security AdminKey
+// @Param namespace query string true "Cache namespace" Enums(jobs, job, locations, applications, application)
+// @Param limit query int false "Maximum number of keys per tier" default(100) maximum(1000)
+// @Success 200 {object} CacheKeysResponse
+// @Failure 400 {object} middleware.Problem "Unknown namespace or invalid limit"
+// @Failure 401 {object} middleware.Problem "Missing or invalid admin API key"
+// @Failure 500 {object} middleware.Problem "Cache operation failed"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /admin/cache/keys [get]
+func GetCacheKeys(c *gin.Context) {
+	namespace := c.Query("namespace")
+
+	limit := defaultCacheKeysLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxCacheKeysLimit {
+			middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", i18n.ErrInvalidQueryParameters)
+			return
+		}
+		limit = n
+	}
+
+	keys, err := cache.NamespaceKeys(c.Request.Context(), namespace, limit)
+	switch {
+	case errors.Is(err, cache.ErrUnknownNamespace):
+		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", i18n.ErrCacheNamespaceUnknown, namespace)
+		return
+	case err != nil:
+		middleware.OperationError(c, err, "Cache Error", i18n.ErrCacheOperationFailed)
+		return
+	}
+
+	if keys == nil {
+		keys = []cache.KeyInfo{}
+	}
+	c.JSON(http.StatusOK, CacheKeysResponse{Namespace: namespace, Keys: keys})
+}
+
+// EvictCacheNamespace godoc
+// @Summary Evict a cache namespace
+// @Description Remove every key of a cache namespace on every instance, leaving other data in Redis untouched
+// @Tags admin
+// @Produce json
+// @Security AdminKey
+// @Param namespace path string true "Cache namespace" Enums(jobs, job, locations, applications, application)
+// @Success 200 {object} CacheEvictResponse
+// @Failure 400 {object} middleware.Problem "Unknown namespace"
+// @Failure 401 {object} middleware.Problem "Missing or invalid admin API key"
+// @Failure 500 {object} middleware.Problem "Cache operation failed"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /admin/cache/namespaces/{namespace} [delete]
+func EvictCacheNamespace(c *gin.Context) {
+	namespace := c.Param("namespace")
+
+	removed, err := cache.EvictNamespace(c.Request.Context(), namespace)
+	switch {
+	case errors.Is(err, cache.ErrUnknownNamespace):
+		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", i18n.ErrCacheNamespaceUnknown, namespace)
+		return
+	case err != nil:
+		middleware.OperationError(c, err, "Cache Error", i18n.ErrCacheOperationFailed)
+		return
+	}
+
+	middleware.Logger(c).Info("Cache namespace evicted", "namespace", namespace, "removed", removed)
+	c.JSON(http.StatusOK, CacheEvictResponse{Namespace: namespace, Removed: removed})
+}
+
+// WarmUpCache godoc
+// @Summary Warm up the cache
+// @Description Load the locations, the first listing pages and the most applied jobs into the cache
+// @Tags admin
+// @Produce json
+// @Security AdminKey
+// @Success 200 {object} WarmUpResult
+// @Failure 401 {object} middleware.Problem "Missing or invalid admin API key"
+// @Failure 500 {object} middleware.Problem "Cache operation failed"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /admin/cache/warm [post]
+func WarmUpCache(c *gin.Context) {
+	result, err := WarmCache(c.Request.Context())
+	if err != nil {
+		middleware.OperationError(c, err, "Cache Error", i18n.ErrCacheOperationFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
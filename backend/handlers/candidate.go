@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"job-portal-backend/auth"
+	"job-portal-backend/cache"
+	"job-portal-backend/database"
+	"job-portal-backend/i18n"
+	"job-portal-backend/mailer"
+	"job-portal-backend/middleware"
+	"job-portal-backend/models"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMagicLink godoc
+// @Summary Request a magic link
+// @Description Email a link that lets the candidate track their applications without a password
+// @Tags candidate
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param request body models.MagicLinkInput true "Email used when applying"
+// @Success 202 {object} map[string]interface{} "Magic link sent if applications exist"
+// @Failure 400 {object} middleware.Problem "Invalid request data"
+// @Router /applications/magic-link [post]
+func RequestMagicLink(c *gin.Context) {
+	input := c.MustGet(middleware.MagicLinkInputKey).(models.MagicLinkInput)
+	email := strings.TrimSpace(input.Email)
+
+	applications, err := models.GetApplicationsByEmail(c.Request.Context(), email)
+	if err != nil {
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchApplicationsFailed)
+		return
+	}
+
+	// Only send a link when there is something to track, but answer the same way
+	// either way so the endpoint cannot be used to discover who applied
+	if len(applications) > 0 {
+		sendMagicLink(email, applications[0].Name)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": middleware.Localize(c, i18n.MsgMagicLinkSent),
+	})
+}
+
+// GetMyApplications godoc
+// @Summary Get my applications
+// @Description Retrieve the applications of the candidate identified by the magic link
+// @Tags candidate
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token query string false "Magic link token (alternative to the Authorization header)"
+// @Success 200 {array} models.Application
+// @Failure 401 {object} middleware.Problem "Invalid or expired magic link"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /candidate/applications [get]
+func GetMyApplications(c *gin.Context) {
+	applications, err := models.GetApplicationsByEmail(c.Request.Context(), c.GetString("candidate_email"))
+	if err != nil {
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchApplicationsFailed)
+		return
+	}
+
+	if applications == nil {
+		applications = []models.Application{}
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+// WithdrawMyApplication godoc
+// @Summary Withdraw an application
+// @Description Withdraw one of the candidate's own applications
+// @Tags candidate
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param token query string false "Magic link token (alternative to the Authorization header)"
+// @Success 200 {object} models.Application
+// @Failure 400 {object} middleware.Problem "Invalid application ID"
+// @Failure 401 {object} middleware.Problem "Invalid or expired magic link"
+// @Failure 404 {object} middleware.Problem "Application not found"
+// @Failure 409 {object} middleware.Problem "Application already withdrawn"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /candidate/applications/{id}/withdraw [post]
+func WithdrawMyApplication(c *gin.Context) {
+	application, ok := findMyApplication(c)
+	if !ok {
+		return
+	}
+
+	if application.Status == models.ApplicationStatusWithdrawn {
+		middleware.CustomError(c, http.StatusConflict, "Conflict", i18n.ErrApplicationWithdrawn)
+		return
+	}
+
+	err := models.WithdrawApplication(c.Request.Context(), application.ID)
+	if errors.Is(err, models.ErrApplicationWithdrawn) {
+		middleware.CustomError(c, http.StatusConflict, "Conflict", i18n.ErrApplicationWithdrawn)
+		return
+	}
+	if err != nil {
+		middleware.OperationError(c, err, "Database Error", i18n.ErrWithdrawApplicationFailed)
+		return
+	}
+
+	cache.InvalidateApplicationCache(c.Request.Context(), application.ID)
+	cache.InvalidateApplicationsCache(c.Request.Context())
+
+	application.Status = models.ApplicationStatusWithdrawn
+	c.JSON(http.StatusOK, application)
+}
+
+// ReplaceMyApplicationCV godoc
+// @Summary Replace application CV
+// @Description Upload a new CV for one of the candidate's own applications
+// @Tags candidate
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param token query string false "Magic link token (alternative to the Authorization header)"
+// @Param cv formData file true "CV file (PDF only, max 5MB)"
+// @Success 200 {object} models.Application
+// @Failure 400 {object} middleware.Problem "Invalid request data"
+// @Failure 401 {object} middleware.Problem "Invalid or expired magic link"
+// @Failure 404 {object} middleware.Problem "Application not found"
+// @Failure 409 {object} middleware.Problem "Application already withdrawn"
+// @Failure 413 {object} middleware.Problem "CV upload too large"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
+// @Router /candidate/applications/{id}/cv [put]
+func ReplaceMyApplicationCV(c *gin.Context) {
+	application, ok := findMyApplication(c)
+	if !ok {
+		return
+	}
+
+	if application.Status == models.ApplicationStatusWithdrawn {
+		middleware.CustomError(c, http.StatusConflict, "Conflict", i18n.ErrApplicationCVLocked)
+		return
+	}
+
+	file, err := c.FormFile("cv")
+	if err != nil {
+		middleware.CustomError(c, http.StatusBadRequest, "File Error", i18n.ErrCVRequired)
+		return
+	}
+
+	// Generate a random filename
+	filename := generateUniqueFilename(file.Filename)
+
+	// Save file - Use /tmp for Railway deployment
+	uploadPath := fmt.Sprintf("/tmp/%s", filename)
+	if err := c.SaveUploadedFile(file, uploadPath); err != nil {
+		middleware.CustomError(c, http.StatusInternalServerError, "File Error", i18n.ErrCVSaveFailed)
+		return
+	}
+
+	err = models.UpdateApplicationCV(c.Request.Context(), application.ID, filename)
+	if errors.Is(err, models.ErrApplicationWithdrawn) {
+		os.Remove(uploadPath)
+		middleware.CustomError(c, http.StatusConflict, "Conflict", i18n.ErrApplicationCVLocked)
+		return
+	}
+	if err != nil {
+		os.Remove(uploadPath)
+		middleware.OperationError(c, err, "Database Error", i18n.ErrUpdateApplicationFailed)
+		return
+	}
+
+	// Remove the previous CV now that nothing references it
+	if application.CVFilename != "" && application.CVFilename != filename {
+		os.Remove(fmt.Sprintf("/tmp/%s", filepath.Base(application.CVFilename)))
+	}
+
+	cache.InvalidateApplicationCache(c.Request.Context(), application.ID)
+	cache.InvalidateApplicationsCache(c.Request.Context())
+
+	application.CVFilename = filename
+	c.JSON(http.StatusOK, application)
+}
+
+// findMyApplication loads the application from the path and checks ownership.
+// It is read from the primary since its status decides whether it may change.
+func findMyApplication(c *gin.Context) (*models.Application, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", i18n.ErrInvalidApplicationID)
+		return nil, false
+	}
+
+	ctx := database.WithPrimary(c.Request.Context())
+	application, err := models.GetApplicationByIDAndEmail(ctx, id, c.GetString("candidate_email"))
+	if err != nil {
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchApplicationFailed)
+		return nil, false
+	}
+
+	// Applications owned by someone else are reported as missing
+	if application == nil {
+		middleware.CustomError(c, http.StatusNotFound, "Not Found", i18n.ErrApplicationNotFound)
+		return nil, false
+	}
+
+	return application, true
+}
+
+// sendMagicLink emails a tracking link in the background
+func sendMagicLink(email, name string) {
+	token, err := auth.GenerateMagicLinkToken(email, auth.DefaultMagicLinkTTL)
+	if err != nil {
+		slog.Error("Error generating magic link", "error", err)
+		return
+	}
+
+	link := buildMagicLink(token)
+	mailer.SendAsync(func() error {
+		return mailer.SendApplicationMagicLink(email, name, link)
+	})
+}
+
+// buildMagicLink returns the frontend URL that carries the token
+func buildMagicLink(token string) string {
+	return strings.TrimRight(frontendURL, "/") + "/applications/track?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import "job-portal-backend/config"
+
+var (
+	// frontendURL is the base URL of links sent to candidates
+	frontendURL = config.Default().FrontendURL
+
+	// warmUpPages and warmUpJobs size the cache warm-up
+	warmUpPages = config.Default().Cache.WarmUpPages
+	warmUpJobs  = config.Default().Cache.WarmUpJobs
+)
+
+// InitHandlers applies the configuration used by the handlers
+func InitHandlers(cfg *config.Config) {
+	frontendURL = cfg.FrontendURL
+	warmUpPages = cfg.Cache.WarmUpPages
+	warmUpJobs = cfg.Cache.WarmUpJobs
+}
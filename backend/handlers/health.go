@@ -2,30 +2,25 @@ package handlers
 
 import (
 	"job-portal-backend/database"
+	"job-portal-backend/health"
 	"net/http"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // HealthResponse represents health check response
 type HealthResponse struct {
-	Status    string         `json:"status"`
-	Timestamp time.Time      `json:"timestamp"`
-	Version   string         `json:"version"`
-	Uptime    string         `json:"uptime"`
-	Database  DatabaseHealth `json:"database"`
-	System    SystemHealth   `json:"system"`
-	Cache     CacheHealth    `json:"cache"`
-}
-
-// DatabaseHealth represents database health status
-type DatabaseHealth struct {
-	Status    string                 `json:"status"`
-	Connected bool                   `json:"connected"`
-	Stats     map[string]interface{} `json:"stats,omitempty"`
-	Error     string                 `json:"error,omitempty"`
+	Status    string                   `json:"status" example:"degraded"`
+	Timestamp time.Time                `json:"timestamp"`
+	Version   string                   `json:"version" example:"1.0.0"`
+	Uptime    string                   `json:"uptime" example:"1h2m3s"`
+	Checks    map[string]health.Result `json:"checks"`
+	System    SystemHealth             `json:"system"`
 }
 
 // SystemHealth represents system health status
@@ -54,53 +49,35 @@ type DiskUsage struct {
 	Percent float64 `json:"percent"`
 }
 
-// CacheHealth represents cache health status
-type CacheHealth struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
-}
-
 var startTime = time.Now()
 
 // HealthCheck godoc
 // @Summary Health check endpoint
-// @Description Comprehensive health check for load balancers
+// @Description Runs every registered dependency check. Returns 200 when healthy or degraded (an optional dependency such as the cache is down) and 503 when a critical dependency is down
 // @Tags health
 // @Accept json
 // @Produce json
 // @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
 // @Router /health [get]
 func HealthCheck(c *gin.Context) {
-	// Check database health
-	dbHealth := checkDatabaseHealth()
-
-	// Check system health
-	sysHealth := checkSystemHealth()
-
-	// Check cache health
-	cacheHealth := checkCacheHealth()
-
-	// Determine overall status
-	status := "healthy"
-	if dbHealth.Status != "healthy" || cacheHealth.Status != "healthy" {
-		status = "unhealthy"
-	}
+	// Run all registered dependency checks concurrently
+	report := health.Run(c.Request.Context())
 
 	response := HealthResponse{
-		Status:    status,
+		Status:    report.Status,
 		Timestamp: time.Now(),
 		Version:   "1.0.0",
 		Uptime:    time.Since(startTime).String(),
-		Database:  dbHealth,
-		System:    sysHealth,
-		Cache:     cacheHealth,
+		Checks:    report.Checks,
+		System:    checkSystemHealth(),
 	}
 
-	// Set appropriate status code
-	if status == "healthy" {
-		c.JSON(http.StatusOK, response)
-	} else {
+	// A degraded service still serves requests, only an unhealthy one is taken out
+	if report.Status == health.StatusUnhealthy {
 		c.JSON(http.StatusServiceUnavailable, response)
+	} else {
+		c.JSON(http.StatusOK, response)
 	}
 }
 
@@ -122,25 +99,41 @@ func LivenessCheck(c *gin.Context) {
 
 // ReadinessCheck godoc
 // @Summary Readiness check endpoint
-// @Description Readiness check for Kubernetes
+// @Description Readiness check for Kubernetes, not ready when a critical dependency is down or the server is shutting down
 // @Tags health
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
 // @Router /health/ready [get]
 func ReadinessCheck(c *gin.Context) {
-	// Check if database is ready
-	dbHealth := checkDatabaseHealth()
+	// Stop receiving traffic as soon as shutdown starts
+	if health.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "not_ready",
+			"timestamp": time.Now(),
+			"reason":    "shutting_down",
+		})
+		return
+	}
+
+	report := health.Run(c.Request.Context())
 
+	// Only failing critical dependencies make the instance not ready
 	status := "ready"
-	if dbHealth.Status != "healthy" {
+	if report.Status == health.StatusUnhealthy {
 		status = "not_ready"
 	}
 
+	checks := make(map[string]string, len(report.Checks))
+	for name, result := range report.Checks {
+		checks[name] = result.Status
+	}
+
 	response := gin.H{
 		"status":    status,
 		"timestamp": time.Now(),
-		"database":  dbHealth.Status,
+		"checks":    checks,
 	}
 
 	if status == "ready" {
@@ -150,28 +143,6 @@ func ReadinessCheck(c *gin.Context) {
 	}
 }
 
-// checkDatabaseHealth checks database connection and returns health status
-func checkDatabaseHealth() DatabaseHealth {
-	// Test database connection
-	err := database.DB.Ping()
-	if err != nil {
-		return DatabaseHealth{
-			Status:    "unhealthy",
-			Connected: false,
-			Error:     err.Error(),
-		}
-	}
-
-	// Get database statistics
-	stats := database.GetDBStats()
-
-	return DatabaseHealth{
-		Status:    "healthy",
-		Connected: true,
-		Stats:     stats,
-	}
-}
-
 // checkSystemHealth checks system resources and returns health status
 func checkSystemHealth() SystemHealth {
 	var m runtime.MemStats
@@ -197,24 +168,21 @@ func checkSystemHealth() SystemHealth {
 	}
 }
 
-// checkCacheHealth checks cache status
-func checkCacheHealth() CacheHealth {
-	// For now, return healthy status
-	// In production, this would check Redis or other cache
-	return CacheHealth{
-		Status: "healthy",
-	}
-}
-
 // Metrics godoc
 // @Summary Metrics endpoint
-// @Description Application metrics for monitoring
+// @Description Prometheus metrics in the text exposition format, or the JSON view with format=json
 // @Tags health
-// @Accept json
+// @Produce plain
 // @Produce json
-// @Success 200 {object} map[string]interface{}
+// @Param format query string false "Response format" Enums(prometheus, json)
+// @Success 200 {string} string "Prometheus text exposition"
 // @Router /metrics [get]
 func Metrics(c *gin.Context) {
+	if c.Query("format") != "json" {
+		prometheusHandler.ServeHTTP(c.Writer, c.Request)
+		return
+	}
+
 	// Get database stats
 	dbStats := database.GetDBStats()
 
@@ -237,7 +205,58 @@ func Metrics(c *gin.Context) {
 			"version": "1.0.0",
 			"status":  "running",
 		},
+		"metrics": metricsSnapshot(),
 	}
 
 	c.JSON(http.StatusOK, metrics)
 }
+
+var prometheusHandler = promhttp.Handler()
+
+// MetricSample is one series of a metric in the JSON view
+type MetricSample struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	Count  uint64            `json:"count,omitempty"`
+}
+
+// metricsSnapshot returns the registered application metrics for the JSON view.
+// Histograms are reported by their sum and count.
+func metricsSnapshot() map[string][]MetricSample {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		return nil
+	}
+
+	snapshot := make(map[string][]MetricSample, len(families))
+	for _, family := range families {
+		// Runtime metrics are already in the system section
+		if strings.HasPrefix(family.GetName(), "go_") || strings.HasPrefix(family.GetName(), "process_") {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			sample := MetricSample{}
+			if len(metric.GetLabel()) > 0 {
+				sample.Labels = make(map[string]string, len(metric.GetLabel()))
+				for _, label := range metric.GetLabel() {
+					sample.Labels[label.GetName()] = label.GetValue()
+				}
+			}
+
+			switch {
+			case metric.Counter != nil:
+				sample.Value = metric.GetCounter().GetValue()
+			case metric.Gauge != nil:
+				sample.Value = metric.GetGauge().GetValue()
+			case metric.Histogram != nil:
+				sample.Value = metric.GetHistogram().GetSampleSum()
+				sample.Count = metric.GetHistogram().GetSampleCount()
+			}
+
+			snapshot[family.GetName()] = append(snapshot[family.GetName()], sample)
+		}
+	}
+
+	return snapshot
+}
@@ -1,120 +1,307 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"job-portal-backend/cache"
+	"job-portal-backend/i18n"
 	"job-portal-backend/middleware"
 	"job-portal-backend/models"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// defaultJobsLimit is the page size of job listings without a limit
+const defaultJobsLimit = 12
+
+// errJobNotFound reports a missing job from the cache loader of GetJobByID
+var errJobNotFound = errors.New("job not found")
+
 type PaginatedResponse struct {
 	Jobs       []models.Job `json:"jobs"`
 	Pagination struct {
-		Page       int  `json:"page"`
-		Limit      int  `json:"limit"`
-		Total      int  `json:"total"`
-		TotalPages int  `json:"total_pages"`
-		HasNext    bool `json:"has_next"`
-		HasPrev    bool `json:"has_prev"`
+		// Page is set for listings selected by page number
+		Page  int `json:"page,omitempty"`
+		Limit int `json:"limit"`
+
+		// Total and TotalPages are left out when counting is skipped.
+		// TotalEstimated reports a count estimated by the query planner.
+		Total          *int `json:"total,omitempty"`
+		TotalPages     *int `json:"total_pages,omitempty"`
+		TotalEstimated bool `json:"total_estimated,omitempty"`
+
+		HasNext    bool   `json:"has_next"`
+		HasPrev    bool   `json:"has_prev"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
 	} `json:"pagination"`
+
+	// Facets is set when facets are requested
+	Facets models.JobFacets `json:"facets,omitempty"`
+}
+
+// jobListing selects a job listing, by page number or from a cursor
+type jobListing struct {
+	Filters models.JobFilter
+	Sort    models.JobSort
+	Page    int
+	Cursor  *models.JobCursor
+	Limit   int
+	Count   string
+	Facets  []string
 }
 
 // GetJobs godoc
 // @Summary Get all jobs with pagination and filters
-// @Description Retrieve a list of jobs with optional filtering and pagination
+// @Description Retrieve a list of jobs, newest first unless sorted, with optional filtering and text search. Pages are selected by page number or by the opaque cursors returned in the pagination, which stay stable while jobs are posted.
 // @Tags jobs
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number (default: 1)"
+// @Param page query int false "Page number (default: 1), cannot be combined with cursor"
 // @Param limit query int false "Number of items per page (default: 12, max: 50)"
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of a previous response"
+// @Param count query string false "How to count the total: exact, estimate or none (default: exact with page, none with cursor)" Enums(exact, estimate, none)
+// @Param q query string false "Search the position, company and description"
+// @Param sort query string false "Comma-separated sort keys (created_at, salary_min, salary_max, company, applications, relevance), each optionally followed by :asc or :desc (default: created_at:desc). relevance requires q. A cursor keeps the sort it was returned with."
 // @Param location query string false "Filter by location"
 // @Param salary_min query int false "Minimum salary filter"
 // @Param salary_max query int false "Maximum salary filter"
+// @Param employment_type query string false "Filter by employment type" Enums(full_time, part_time, contract, internship)
+// @Param company query string false "Filter by company"
+// @Param facets query string false "Comma-separated facets to count for the filters (location, salary, employment_type, company)"
 // @Success 200 {object} PaginatedResponse
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 400 {object} middleware.Problem "Invalid query parameters"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
 // @Router /jobs [get]
 func GetJobs(c *gin.Context) {
-	// Parse query parameters untuk filter
-	location := c.Query("location")
-	salaryMinStr := c.Query("salary_min")
-	salaryMaxStr := c.Query("salary_max")
+	query := c.MustGet(middleware.JobListQueryKey).(models.JobListQuery)
+
+	listing := jobListing{
+		Filters: models.JobFilter{
+			Query:     query.Q,
+			Location:  query.Location,
+			SalaryMin: query.SalaryMin,
+			SalaryMax: query.SalaryMax,
+
+			EmploymentType: query.EmploymentType,
+			Company:        query.Company,
+		},
+		Sort:  models.DefaultJobSort,
+		Limit: defaultJobsLimit,
+		Count: query.Count,
+	}
 
-	// Parse pagination parameters
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "12")
+	// The sort and facets were checked by the validation
+	if query.Sort != "" {
+		listing.Sort, _ = models.ParseJobSort(query.Sort)
+	}
+	if query.Facets != "" {
+		listing.Facets, _ = models.ParseJobFacets(query.Facets)
+	}
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	if query.Limit != nil {
+		listing.Limit = *query.Limit
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 12
+	// Cursor listings skip counting unless asked, since avoiding the cost of
+	// deep pages is their point. The cursor was checked by the validation,
+	// and carries its sort.
+	if query.Cursor != "" {
+		cursor, _ := models.DecodeJobCursor(query.Cursor)
+		listing.Cursor = &cursor
+		listing.Sort = cursor.Sort
+		if listing.Count == "" {
+			listing.Count = models.CountNone
+		}
+	} else {
+		listing.Page = 1
+		if query.Page != nil {
+			listing.Page = *query.Page
+		}
+		if listing.Count == "" {
+			listing.Count = models.CountExact
+		}
 	}
 
-	// Set maximum limit
-	if limit > 50 {
-		limit = 50
+	response, err := fetchJobListing(c.Request.Context(), listing)
+	if err != nil {
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchJobsFailed)
+		return
 	}
 
-	filters := models.JobFilter{
-		Location: location,
+	c.JSON(http.StatusOK, response)
+}
+
+// fetchJobListing reads a job listing through the cache. Without a listing
+// key the jobs are loaded directly.
+func fetchJobListing(ctx context.Context, listing jobListing) (PaginatedResponse, error) {
+	cacheKey, err := cache.JobListingKey(ctx, jobListingParams(listing))
+	if err != nil {
+		return loadJobListing(ctx, listing)
 	}
 
-	// Parse salary filters
-	if salaryMinStr != "" {
-		if salaryMin, err := strconv.Atoi(salaryMinStr); err == nil {
-			filters.SalaryMin = salaryMin
-		}
+	var response PaginatedResponse
+	err = cache.FetchJobs(ctx, cacheKey, &response, func(ctx context.Context) (interface{}, error) {
+		return loadJobListing(ctx, listing)
+	})
+	return response, err
+}
+
+// loadJobListing loads a job listing from the database
+func loadJobListing(ctx context.Context, listing jobListing) (PaginatedResponse, error) {
+	var response PaginatedResponse
+	response.Pagination.Limit = listing.Limit
+
+	// One job more than the limit is loaded to tell whether more follow
+	var jobs []models.Job
+	var err error
+	if listing.Cursor != nil {
+		jobs, err = models.GetJobsByCursor(ctx, listing.Filters, *listing.Cursor, listing.Limit)
+	} else {
+		jobs, err = models.GetJobsWithPagination(ctx, listing.Filters, listing.Sort, listing.Page, listing.Limit)
+	}
+	if err != nil {
+		return PaginatedResponse{}, err
+	}
+
+	hasMore := len(jobs) > listing.Limit
+	switch {
+	case !hasMore:
+	case listing.Cursor != nil && listing.Cursor.Before:
+		// The extra job of a backward listing is the newest one
+		jobs = jobs[1:]
+	default:
+		jobs = jobs[:listing.Limit]
+	}
+	response.Jobs = jobs
+
+	switch {
+	case listing.Cursor == nil:
+		response.Pagination.Page = listing.Page
+		response.Pagination.HasNext = hasMore
+		response.Pagination.HasPrev = listing.Page > 1
+	case listing.Cursor.Before:
+		response.Pagination.HasNext = true
+		response.Pagination.HasPrev = hasMore
+	default:
+		response.Pagination.HasNext = hasMore
+		response.Pagination.HasPrev = true
+	}
+
+	setJobListingCursors(&response, listing)
+
+	if err := countJobListing(ctx, &response, listing); err != nil {
+		return PaginatedResponse{}, err
 	}
 
-	if salaryMaxStr != "" {
-		if salaryMax, err := strconv.Atoi(salaryMaxStr); err == nil {
-			filters.SalaryMax = salaryMax
+	if len(listing.Facets) > 0 {
+		response.Facets, err = models.GetJobFacets(ctx, listing.Filters, listing.Facets)
+		if err != nil {
+			return PaginatedResponse{}, err
 		}
 	}
 
-	// Try to get from cache first (only for unfiltered requests)
-	var response PaginatedResponse
-	if location == "" && salaryMinStr == "" && salaryMaxStr == "" && page == 1 {
-		if err := cache.GetCachedJobs(&response); err == nil {
-			c.JSON(http.StatusOK, response)
-			return
+	return response, nil
+}
+
+// setJobListingCursors sets the cursors reading the jobs next to the
+// listing. When a cursor listing is empty, the cursor it was read from
+// is turned around so that the client can go back.
+func setJobListingCursors(response *PaginatedResponse, listing jobListing) {
+	pagination := &response.Pagination
+	jobs := response.Jobs
+
+	if len(jobs) == 0 {
+		if listing.Cursor != nil && pagination.HasNext {
+			next := *listing.Cursor
+			next.Before = false
+			pagination.NextCursor = next.Encode()
 		}
+		if listing.Cursor != nil && pagination.HasPrev {
+			prev := *listing.Cursor
+			prev.Before = true
+			pagination.PrevCursor = prev.Encode()
+		}
+		return
 	}
 
-	// Get jobs with pagination
-	jobs, total, err := models.GetJobsWithPagination(filters, page, limit)
+	if pagination.HasNext {
+		pagination.NextCursor = listing.Sort.CursorAfter(jobs[len(jobs)-1]).Encode()
+	}
+	if pagination.HasPrev {
+		pagination.PrevCursor = listing.Sort.CursorBefore(jobs[0]).Encode()
+	}
+}
+
+// countJobListing sets the total of the listing as selected by its count mode
+func countJobListing(ctx context.Context, response *PaginatedResponse, listing jobListing) error {
+	var total int
+	var err error
+	switch listing.Count {
+	case models.CountExact:
+		total, err = models.CountJobs(ctx, listing.Filters)
+	case models.CountEstimate:
+		total, err = models.EstimateJobs(ctx, listing.Filters)
+		response.Pagination.TotalEstimated = true
+	default:
+		return nil
+	}
 	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to fetch jobs")
-		return
+		return err
 	}
 
-	// Calculate pagination info
-	totalPages := (total + limit - 1) / limit
-	hasNext := page < totalPages
-	hasPrev := page > 1
+	response.Pagination.Total = &total
+	if listing.Cursor == nil {
+		totalPages := (total + listing.Limit - 1) / listing.Limit
+		response.Pagination.TotalPages = &totalPages
+	}
+	return nil
+}
 
-	response = PaginatedResponse{
-		Jobs: jobs,
+// jobListingParams returns the parameters that select a job listing, used to
+// build its cache key. Unset filters are left out so that equivalent requests
+// share a key.
+func jobListingParams(listing jobListing) url.Values {
+	params := url.Values{}
+	if listing.Cursor != nil {
+		params.Set("cursor", listing.Cursor.Encode())
+	} else {
+		params.Set("page", strconv.Itoa(listing.Page))
+	}
+	params.Set("limit", strconv.Itoa(listing.Limit))
+	params.Set("count", listing.Count)
+	params.Set("sort", listing.Sort.String())
+	if len(listing.Facets) > 0 {
+		params.Set("facets", strings.Join(listing.Facets, ","))
 	}
-	response.Pagination.Page = page
-	response.Pagination.Limit = limit
-	response.Pagination.Total = total
-	response.Pagination.TotalPages = totalPages
-	response.Pagination.HasNext = hasNext
-	response.Pagination.HasPrev = hasPrev
 
-	// Cache the result if it's the first page without filters
-	if location == "" && salaryMinStr == "" && salaryMaxStr == "" && page == 1 {
-		cache.CacheJobs(response)
+	filters := listing.Filters
+	if filters.Query != "" {
+		params.Set("q", filters.Query)
+	}
+	if filters.Location != "" {
+		params.Set("location", filters.Location)
+	}
+	if filters.SalaryMin > 0 {
+		params.Set("salary_min", strconv.Itoa(filters.SalaryMin))
+	}
+	if filters.SalaryMax > 0 {
+		params.Set("salary_max", strconv.Itoa(filters.SalaryMax))
+	}
+	if filters.EmploymentType != "" {
+		params.Set("employment_type", filters.EmploymentType)
+	}
+	if filters.Company != "" {
+		params.Set("company", filters.Company)
 	}
 
-	c.JSON(http.StatusOK, response)
+	return params
 }
 
 // GetJobByID godoc
@@ -125,79 +312,73 @@ func GetJobs(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Job ID"
 // @Success 200 {object} models.Job
-// @Failure 400 {object} map[string]interface{} "Invalid job ID"
-// @Failure 404 {object} map[string]interface{} "Job not found"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 400 {object} middleware.Problem "Invalid job ID"
+// @Failure 404 {object} middleware.Problem "Job not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
 // @Router /jobs/{id} [get]
 func GetJobByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", "Invalid job ID")
+		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", i18n.ErrInvalidJobID)
 		return
 	}
 
-	// Try to get from cache first
-	var job *models.Job
-	if err := cache.GetCachedJob(id, &job); err == nil {
-		c.JSON(http.StatusOK, job)
+	job, err := fetchJob(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, errJobNotFound):
+		middleware.CustomError(c, http.StatusNotFound, "Not Found", i18n.ErrJobNotFound)
 		return
-	}
-
-	job, err = models.GetJobByID(id)
-	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to fetch job")
-		return
-	}
-
-	if job == nil {
-		middleware.CustomError(c, http.StatusNotFound, "Not Found", "Job not found")
+	case err != nil:
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchJobFailed)
 		return
 	}
 
-	// Cache the job
-	cache.CacheJob(id, job)
-
 	c.JSON(http.StatusOK, job)
 }
 
+// fetchJob reads a job through the cache. Missing jobs are not cached and
+// are reported as errJobNotFound.
+func fetchJob(ctx context.Context, id int) (models.Job, error) {
+	var job models.Job
+	err := cache.FetchJob(ctx, id, &job, func(ctx context.Context) (interface{}, error) {
+		job, err := models.GetJobByID(ctx, id)
+		if err == nil && job == nil {
+			return nil, errJobNotFound
+		}
+		return job, err
+	})
+	return job, err
+}
+
 // CreateJob godoc
 // @Summary Create a new job
 // @Description Create a new job posting
 // @Tags jobs
-// @Accept json
+// @Accept json,x-www-form-urlencoded
 // @Produce json
-// @Param job body models.Job true "Job object"
+// @Param job body models.JobInput true "Job object"
 // @Success 201 {object} models.Job
-// @Failure 400 {object} map[string]interface{} "Invalid request data"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 400 {object} middleware.Problem "Invalid request data"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
 // @Router /jobs [post]
 func CreateJob(c *gin.Context) {
-	var job models.Job
-	if err := c.ShouldBindJSON(&job); err != nil {
-		middleware.CustomError(c, http.StatusBadRequest, "Invalid Input", "Invalid request data")
-		return
-	}
+	input := c.MustGet(middleware.JobInputKey).(models.JobInput)
+	job := input.ToJob()
 
-	// Validasi data
-	if job.Position == "" || job.Company == "" || job.Location == "" {
-		middleware.CustomError(c, http.StatusBadRequest, "Validation Error", "Position, company, and location are required")
-		return
-	}
-
-	if job.SalaryMin <= 0 || job.SalaryMax <= 0 || job.SalaryMin > job.SalaryMax {
-		middleware.CustomError(c, http.StatusBadRequest, "Validation Error", "Invalid salary range")
-		return
-	}
-
-	err := models.CreateJob(&job)
+	err := models.CreateJob(c.Request.Context(), &job)
 	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to create job")
+		middleware.OperationError(c, err, "Database Error", i18n.ErrCreateJobFailed)
 		return
 	}
 
-	// Invalidate cache
-	cache.InvalidateJobsCache()
+	// Invalidate every cached listing, and the locations in case the job adds one
+	cache.InvalidateJobsCache(c.Request.Context())
+	cache.InvalidateLocationsCache(c.Request.Context())
 
 	c.JSON(http.StatusCreated, job)
 }
@@ -209,24 +390,25 @@ func CreateJob(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {array} string
-// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Failure 503 {object} middleware.Problem "Database or cache unavailable"
+// @Failure 504 {object} middleware.Problem "Operation timed out"
 // @Router /locations [get]
 func GetLocations(c *gin.Context) {
-	// Try to get from cache first
-	var locations []string
-	if err := cache.GetCachedLocations(&locations); err == nil {
-		c.JSON(http.StatusOK, locations)
-		return
-	}
-
-	locations, err := models.GetLocations()
+	locations, err := fetchLocations(c.Request.Context())
 	if err != nil {
-		middleware.CustomError(c, http.StatusInternalServerError, "Database Error", "Failed to fetch locations")
+		middleware.OperationError(c, err, "Database Error", i18n.ErrFetchLocationsFailed)
 		return
 	}
 
-	// Cache the locations
-	cache.CacheLocations(locations)
-
 	c.JSON(http.StatusOK, locations)
 }
+
+// fetchLocations reads the job locations through the cache
+func fetchLocations(ctx context.Context) ([]string, error) {
+	var locations []string
+	err := cache.FetchLocations(ctx, &locations, func(ctx context.Context) (interface{}, error) {
+		return models.GetLocations(ctx)
+	})
+	return locations, err
+}
@@ -0,0 +1,165 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a check and of the service as a whole
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// DefaultTimeout bounds a check registered without its own timeout
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc probes a dependency. It returns details to report, such as pool
+// statistics, and an error when the dependency is not usable.
+type CheckFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// Checker is a registered dependency check
+type Checker struct {
+	Name    string
+	Check   CheckFunc
+	Timeout time.Duration
+
+	// Critical checks make the service unhealthy and not ready when they fail.
+	// Failing non-critical checks only degrade it.
+	Critical bool
+}
+
+// Result is the outcome of one check
+// @Description Outcome of a dependency check
+type Result struct {
+	Status   string                 `json:"status" example:"healthy"`
+	Critical bool                   `json:"critical" example:"true"`
+	Latency  string                 `json:"latency" example:"1.2ms"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// Report is the outcome of all registered checks
+type Report struct {
+	Status string
+	Checks map[string]Result
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = map[string]Checker{}
+)
+
+// draining is set once shutdown has started
+var draining atomic.Bool
+
+// SetDraining marks the service as shutting down so readiness checks fail and
+// load balancers stop sending new requests
+func SetDraining() {
+	draining.Store(true)
+}
+
+// Draining reports whether shutdown has started
+func Draining() bool {
+	return draining.Load()
+}
+
+// Register adds a check, replacing any check with the same name
+func Register(checker Checker) {
+	if checker.Timeout <= 0 {
+		checker.Timeout = DefaultTimeout
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[checker.Name] = checker
+}
+
+// Run executes all registered checks concurrently, each bounded by its own
+// timeout. The service is unhealthy when a critical check fails and degraded
+// when only non-critical checks fail.
+func Run(ctx context.Context) Report {
+	mu.RLock()
+	list := make([]Checker, 0, len(checkers))
+	for _, checker := range checkers {
+		list = append(list, checker)
+	}
+	mu.RUnlock()
+
+	results := make([]Result, len(list))
+	var wg sync.WaitGroup
+	for i, checker := range list {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusHealthy, Checks: make(map[string]Result, len(list))}
+	for i, checker := range list {
+		result := results[i]
+		report.Checks[checker.Name] = result
+
+		if result.Status == StatusHealthy {
+			continue
+		}
+		if checker.Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// errTimeout is reported for checks that ignore their context and overrun
+var errTimeout = errors.New("check timed out")
+
+// run executes one check, giving up when its timeout expires even if the
+// check itself does not honour the context
+func run(ctx context.Context, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, checker.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+
+	start := time.Now()
+	go func() {
+		details, err := checker.Check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = errTimeout
+	}
+
+	result := Result{
+		Status:   StatusHealthy,
+		Critical: checker.Critical,
+		Latency:  time.Since(start).String(),
+		Details:  o.details,
+	}
+	if o.err != nil {
+		result.Status = StatusUnhealthy
+		if errors.Is(o.err, context.DeadlineExceeded) {
+			o.err = errTimeout
+		}
+		result.Error = o.err.Error()
+	}
+
+	return result
+}
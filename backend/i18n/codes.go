@@ -0,0 +1,70 @@
+package i18n
+
+// Error and message codes. Codes are part of the API contract: clients may
+// localize on their own using them, so existing codes must never change.
+const (
+	// General errors
+	ErrInternal               = "INTERNAL_ERROR"
+	ErrInvalidRequestData     = "INVALID_REQUEST_DATA"
+	ErrValidationFailed       = "VALIDATION_FAILED"
+	ErrInvalidQueryParameters = "INVALID_QUERY_PARAMETERS"
+	ErrRateLimitExceeded      = "RATE_LIMIT_EXCEEDED"
+	ErrRouteNotFound          = "ROUTE_NOT_FOUND"
+	ErrPayloadTooLarge        = "PAYLOAD_TOO_LARGE"
+	ErrOperationTimeout       = "OPERATION_TIMEOUT"
+	ErrServiceUnavailable     = "SERVICE_UNAVAILABLE"
+
+	// Validation rules
+	ErrFieldRequired          = "FIELD_REQUIRED"
+	ErrFieldInvalidCharacters = "FIELD_INVALID_CHARACTERS"
+	ErrFieldInvalidEmail      = "FIELD_INVALID_EMAIL"
+	ErrFieldMustBePositive    = "FIELD_MUST_BE_POSITIVE"
+	ErrFieldTooLarge          = "FIELD_TOO_LARGE"
+	ErrFieldTooLong           = "FIELD_TOO_LONG"
+	ErrFieldInvalidNumber     = "FIELD_INVALID_NUMBER"
+	ErrFieldOneOf             = "FIELD_ONE_OF"
+	ErrSalaryRangeInvalid     = "SALARY_RANGE_INVALID"
+	ErrPageInvalid            = "PAGE_INVALID"
+	ErrLimitOutOfRange        = "LIMIT_OUT_OF_RANGE"
+	ErrCursorInvalid          = "CURSOR_INVALID"
+	ErrCursorWithPage         = "CURSOR_WITH_PAGE"
+	ErrCursorSortMismatch     = "CURSOR_SORT_MISMATCH"
+	ErrSortInvalid            = "SORT_INVALID"
+	ErrSortRequiresQuery      = "SORT_REQUIRES_QUERY"
+	ErrFacetsInvalid          = "FACETS_INVALID"
+	ErrCVRequired             = "CV_REQUIRED"
+	ErrCVPDFOnly              = "CV_PDF_ONLY"
+	ErrCVTooLarge             = "CV_TOO_LARGE"
+	ErrCVFilenameTooLong      = "CV_FILENAME_TOO_LONG"
+
+	// Jobs
+	ErrInvalidJobID         = "INVALID_JOB_ID"
+	ErrJobNotFound          = "JOB_NOT_FOUND"
+	ErrFetchJobsFailed      = "FETCH_JOBS_FAILED"
+	ErrFetchJobFailed       = "FETCH_JOB_FAILED"
+	ErrCreateJobFailed      = "CREATE_JOB_FAILED"
+	ErrFetchLocationsFailed = "FETCH_LOCATIONS_FAILED"
+
+	// Applications
+	ErrInvalidApplicationID      = "INVALID_APPLICATION_ID"
+	ErrApplicationNotFound       = "APPLICATION_NOT_FOUND"
+	ErrApplicationWithdrawn      = "APPLICATION_ALREADY_WITHDRAWN"
+	ErrApplicationCVLocked       = "APPLICATION_CV_LOCKED"
+	ErrFetchApplicationsFailed   = "FETCH_APPLICATIONS_FAILED"
+	ErrFetchApplicationFailed    = "FETCH_APPLICATION_FAILED"
+	ErrCreateApplicationFailed   = "CREATE_APPLICATION_FAILED"
+	ErrUpdateApplicationFailed   = "UPDATE_APPLICATION_FAILED"
+	ErrWithdrawApplicationFailed = "WITHDRAW_APPLICATION_FAILED"
+	ErrCVSaveFailed              = "CV_SAVE_FAILED"
+	ErrMagicLinkRequired         = "MAGIC_LINK_REQUIRED"
+	ErrMagicLinkInvalid          = "MAGIC_LINK_INVALID"
+	ErrMagicLinkExpired          = "MAGIC_LINK_EXPIRED"
+	MsgApplicationSubmitted      = "APPLICATION_SUBMITTED"
+	MsgMagicLinkSent             = "MAGIC_LINK_SENT"
+
+	// Admin
+	ErrAdminKeyRequired      = "ADMIN_KEY_REQUIRED"
+	ErrAdminKeyInvalid       = "ADMIN_KEY_INVALID"
+	ErrCacheNamespaceUnknown = "CACHE_NAMESPACE_UNKNOWN"
+	ErrCacheOperationFailed  = "CACHE_OPERATION_FAILED"
+)
@@ -0,0 +1,122 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported locales
+const (
+	Indonesian = "id"
+	English    = "en"
+)
+
+// catalogs holds the messages of every supported locale keyed by error code
+var catalogs = map[string]map[string]string{
+	Indonesian: messagesID,
+	English:    messagesEN,
+}
+
+// labels holds the localized names of request fields
+var labels = map[string]map[string]string{
+	Indonesian: labelsID,
+	English:    labelsEN,
+}
+
+var defaultLocale = Indonesian
+
+// InitLocale sets the default locale, keeping Indonesian for unsupported locales
+func InitLocale(locale string) {
+	locale = strings.ToLower(locale)
+	if _, ok := catalogs[locale]; ok {
+		defaultLocale = locale
+	}
+}
+
+// DefaultLocale returns the locale used when negotiation finds no match
+func DefaultLocale() string {
+	return defaultLocale
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		tag, q := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = strings.TrimSpace(part[:i])
+			param := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					continue
+				}
+				q = value
+			}
+		}
+
+		// Match on the primary subtag so "id-ID" and "en-US" are supported
+		primary := strings.ToLower(strings.Split(tag, "-")[0])
+		if _, ok := catalogs[primary]; ok && q > 0 {
+			candidates = append(candidates, candidate{locale: primary, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return defaultLocale
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	return candidates[0].locale
+}
+
+// T returns the message for code in locale, formatted with args
+func T(locale, code string, args ...interface{}) string {
+	message, ok := catalogs[locale][code]
+	if !ok {
+		message, ok = catalogs[defaultLocale][code]
+	}
+	if !ok {
+		message, ok = catalogs[English][code]
+	}
+	if !ok {
+		return code
+	}
+
+	// Messages use only as many arguments as they have verbs, so callers can
+	// pass the same arguments to every code
+	verbs := strings.Count(message, "%") - 2*strings.Count(message, "%%")
+	if verbs == 0 {
+		return message
+	}
+	if len(args) > verbs {
+		args = args[:verbs]
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Label returns the localized name of a request field
+func Label(locale, field string) string {
+	if label, ok := labels[locale][field]; ok {
+		return label
+	}
+	if label, ok := labels[English][field]; ok {
+		return label
+	}
+	return field
+}
@@ -0,0 +1,87 @@
+package i18n
+
+// messagesEN holds the English messages
+var messagesEN = map[string]string{
+	ErrInternal:               "An unexpected error occurred",
+	ErrInvalidRequestData:     "Invalid request data",
+	ErrValidationFailed:       "Validation failed",
+	ErrInvalidQueryParameters: "Invalid query parameters",
+	ErrRouteNotFound:          "Route not found",
+	ErrRateLimitExceeded:      "Too many requests. Please try again later.",
+	ErrPayloadTooLarge:        "Request body must not exceed %s",
+	ErrOperationTimeout:       "The request took too long to process. Please try again.",
+	ErrServiceUnavailable:     "The service is temporarily unavailable. Please try again later.",
+
+	ErrFieldRequired:          "%s is required",
+	ErrFieldInvalidCharacters: "%s contains invalid characters",
+	ErrFieldInvalidEmail:      "Invalid email format",
+	ErrFieldMustBePositive:    "%s must be positive",
+	ErrFieldTooLarge:          "%s must be at most %s",
+	ErrFieldTooLong:           "%s must be at most %s characters",
+	ErrFieldInvalidNumber:     "%s must be a valid number",
+	ErrFieldOneOf:             "%s must be one of: %s",
+	ErrSalaryRangeInvalid:     "Minimum salary cannot be greater than maximum salary",
+	ErrPageInvalid:            "Page must be a positive number",
+	ErrLimitOutOfRange:        "Limit must be between 1 and 100",
+	ErrCursorInvalid:          "Invalid cursor",
+	ErrCursorWithPage:         "Cursor cannot be combined with page",
+	ErrCursorSortMismatch:     "Cursor was returned for a different sort",
+	ErrSortInvalid:            "Sort must list up to 3 of created_at, salary_min, salary_max, company, applications and relevance, each optionally followed by :asc or :desc",
+	ErrSortRequiresQuery:      "Sorting by relevance requires a search query",
+	ErrFacetsInvalid:          "Facets must list location, salary, employment_type or company, separated by commas",
+	ErrCVRequired:             "CV file is required",
+	ErrCVPDFOnly:              "Only PDF files are allowed",
+	ErrCVTooLarge:             "File size must be less than 5MB",
+	ErrCVFilenameTooLong:      "Filename too long",
+
+	ErrInvalidJobID:         "Invalid job ID",
+	ErrJobNotFound:          "Job not found",
+	ErrFetchJobsFailed:      "Failed to fetch jobs",
+	ErrFetchJobFailed:       "Failed to fetch job",
+	ErrCreateJobFailed:      "Failed to create job",
+	ErrFetchLocationsFailed: "Failed to fetch locations",
+
+	ErrInvalidApplicationID:      "Invalid application ID",
+	ErrApplicationNotFound:       "Application not found",
+	ErrApplicationWithdrawn:      "Application has already been withdrawn",
+	ErrApplicationCVLocked:       "Cannot replace the CV of a withdrawn application",
+	ErrFetchApplicationsFailed:   "Failed to fetch applications",
+	ErrFetchApplicationFailed:    "Failed to fetch application",
+	ErrCreateApplicationFailed:   "Failed to create application",
+	ErrUpdateApplicationFailed:   "Failed to update application",
+	ErrWithdrawApplicationFailed: "Failed to withdraw application",
+	ErrCVSaveFailed:              "Failed to save file",
+	ErrMagicLinkRequired:         "Magic link token is required",
+	ErrMagicLinkInvalid:          "Invalid magic link",
+	ErrMagicLinkExpired:          "Magic link has expired, please request a new one",
+	MsgApplicationSubmitted:      "Application submitted successfully",
+	MsgMagicLinkSent:             "If applications exist for this email, a tracking link has been sent",
+
+	ErrAdminKeyRequired:      "Admin API key is required",
+	ErrAdminKeyInvalid:       "Invalid admin API key",
+	ErrCacheNamespaceUnknown: "Unknown cache namespace %s",
+	ErrCacheOperationFailed:  "Cache operation failed",
+}
+
+// labelsEN holds the English field names
+var labelsEN = map[string]string{
+	"position":    "Position",
+	"company":     "Company",
+	"location":    "Location",
+	"description": "Description",
+	"salary_min":  "Minimum salary",
+	"salary_max":  "Maximum salary",
+	"name":        "Name",
+	"email":       "Email",
+	"job_id":      "Job ID",
+	"page":        "Page",
+	"limit":       "Limit",
+	"cursor":      "Cursor",
+	"count":       "Count",
+	"q":           "Search",
+	"sort":        "Sort",
+	"facets":      "Facets",
+	"cv":          "CV",
+
+	"employment_type": "Employment type",
+}
@@ -0,0 +1,87 @@
+package i18n
+
+// messagesID holds the Indonesian messages
+var messagesID = map[string]string{
+	ErrInternal:               "Terjadi kesalahan yang tidak terduga",
+	ErrInvalidRequestData:     "Data permintaan tidak valid",
+	ErrValidationFailed:       "Validasi input gagal",
+	ErrInvalidQueryParameters: "Parameter query tidak valid",
+	ErrRouteNotFound:          "Rute tidak ditemukan",
+	ErrRateLimitExceeded:      "Terlalu banyak permintaan. Silakan coba lagi nanti.",
+	ErrPayloadTooLarge:        "Ukuran request tidak boleh melebihi %s",
+	ErrOperationTimeout:       "Permintaan terlalu lama diproses. Silakan coba lagi.",
+	ErrServiceUnavailable:     "Layanan sedang tidak tersedia. Silakan coba lagi nanti.",
+
+	ErrFieldRequired:          "%s wajib diisi",
+	ErrFieldInvalidCharacters: "%s mengandung karakter yang tidak valid",
+	ErrFieldInvalidEmail:      "Format email tidak valid",
+	ErrFieldMustBePositive:    "%s harus bernilai positif",
+	ErrFieldTooLarge:          "%s maksimal %s",
+	ErrFieldTooLong:           "%s maksimal %s karakter",
+	ErrFieldInvalidNumber:     "%s harus berupa angka yang valid",
+	ErrFieldOneOf:             "%s harus salah satu dari: %s",
+	ErrSalaryRangeInvalid:     "Gaji minimum tidak boleh lebih besar dari gaji maksimum",
+	ErrPageInvalid:            "Halaman harus berupa angka positif",
+	ErrLimitOutOfRange:        "Limit harus antara 1 dan 100",
+	ErrCursorInvalid:          "Cursor tidak valid",
+	ErrCursorWithPage:         "Cursor tidak dapat digabung dengan halaman",
+	ErrCursorSortMismatch:     "Cursor berasal dari urutan yang berbeda",
+	ErrSortInvalid:            "Urutan harus berisi maksimal 3 dari created_at, salary_min, salary_max, company, applications, dan relevance, masing-masing dapat diikuti :asc atau :desc",
+	ErrSortRequiresQuery:      "Urutan berdasarkan relevansi memerlukan kata kunci pencarian",
+	ErrFacetsInvalid:          "Facets harus berisi location, salary, employment_type, atau company, dipisahkan koma",
+	ErrCVRequired:             "File CV wajib diunggah",
+	ErrCVPDFOnly:              "Hanya file PDF yang diperbolehkan",
+	ErrCVTooLarge:             "Ukuran file harus kurang dari 5MB",
+	ErrCVFilenameTooLong:      "Nama file terlalu panjang",
+
+	ErrInvalidJobID:         "ID lowongan tidak valid",
+	ErrJobNotFound:          "Lowongan tidak ditemukan",
+	ErrFetchJobsFailed:      "Gagal mengambil data lowongan",
+	ErrFetchJobFailed:       "Gagal mengambil data lowongan",
+	ErrCreateJobFailed:      "Gagal membuat lowongan",
+	ErrFetchLocationsFailed: "Gagal mengambil data lokasi",
+
+	ErrInvalidApplicationID:      "ID lamaran tidak valid",
+	ErrApplicationNotFound:       "Lamaran tidak ditemukan",
+	ErrApplicationWithdrawn:      "Lamaran sudah ditarik",
+	ErrApplicationCVLocked:       "CV tidak dapat diganti karena lamaran sudah ditarik",
+	ErrFetchApplicationsFailed:   "Gagal mengambil data lamaran",
+	ErrFetchApplicationFailed:    "Gagal mengambil data lamaran",
+	ErrCreateApplicationFailed:   "Gagal mengirim lamaran",
+	ErrUpdateApplicationFailed:   "Gagal memperbarui lamaran",
+	ErrWithdrawApplicationFailed: "Gagal menarik lamaran",
+	ErrCVSaveFailed:              "Gagal menyimpan file",
+	ErrMagicLinkRequired:         "Token magic link wajib disertakan",
+	ErrMagicLinkInvalid:          "Magic link tidak valid",
+	ErrMagicLinkExpired:          "Magic link sudah kedaluwarsa, silakan minta yang baru",
+	MsgApplicationSubmitted:      "Lamaran berhasil dikirim",
+	MsgMagicLinkSent:             "Jika ada lamaran dengan email ini, tautan pelacakan telah dikirim",
+
+	ErrAdminKeyRequired:      "API key admin wajib disertakan",
+	ErrAdminKeyInvalid:       "API key admin tidak valid",
+	ErrCacheNamespaceUnknown: "Namespace cache %s tidak dikenal",
+	ErrCacheOperationFailed:  "Operasi cache gagal",
+}
+
+// labelsID holds the Indonesian field names
+var labelsID = map[string]string{
+	"position":    "Posisi",
+	"company":     "Perusahaan",
+	"location":    "Lokasi",
+	"description": "Deskripsi",
+	"salary_min":  "Gaji minimum",
+	"salary_max":  "Gaji maksimum",
+	"name":        "Nama",
+	"email":       "Email",
+	"job_id":      "ID lowongan",
+	"page":        "Halaman",
+	"limit":       "Limit",
+	"cursor":      "Cursor",
+	"count":       "Mode hitung",
+	"q":           "Pencarian",
+	"sort":        "Urutan",
+	"facets":      "Facets",
+	"cv":          "CV",
+
+	"employment_type": "Jenis pekerjaan",
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"job-portal-backend/config"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Log formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// contextKey is the type of the request logger context key
+type contextKey struct{}
+
+// redactedKeys are attributes holding personal data
+var redactedKeys = map[string]bool{
+	"email":           true,
+	"name":            true,
+	"user":            true,
+	"candidate_email": true,
+}
+
+var redact = true
+
+// Init configures the default logger with the level (debug, info, warn, error),
+// format (json or text) and PII redaction of cfg. Output of the standard log
+// package is routed through the same handler.
+func Init(cfg config.LogConfig) {
+	redact = cfg.RedactPII
+
+	slog.SetDefault(New(os.Stdout, cfg.Level, cfg.Format))
+}
+
+// New creates a logger writing to w with the given level and format
+func New(w io.Writer, level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       parseLevel(level),
+		ReplaceAttr: replaceAttr,
+	}
+
+	if strings.EqualFold(format, FormatText) {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// parseLevel parses a level name, defaulting to info
+func parseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// replaceAttr redacts personal data before it is written
+func replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if !redact || !redactedKeys[attr.Key] || attr.Value.Kind() != slog.KindString {
+		return attr
+	}
+
+	value := attr.Value.String()
+	if strings.Contains(value, "@") {
+		return slog.String(attr.Key, RedactEmail(value))
+	}
+	return slog.String(attr.Key, RedactName(value))
+}
+
+// RedactEmail keeps the first character of the local part and the domain
+func RedactEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// RedactName keeps only the first character of a name
+func RedactName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return string([]rune(name)[:1]) + "***"
+}
+
+// WithContext returns a copy of ctx carrying logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request logger of ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Fatal logs at error level and exits
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
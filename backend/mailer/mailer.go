@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"job-portal-backend/config"
+	"log/slog"
+	"net/smtp"
+	"strings"
+	"sync"
+)
+
+var mailConfig *config.MailConfig
+
+// pending tracks emails sent in the background
+var pending sync.WaitGroup
+
+// InitMailer initializes the SMTP mailer
+func InitMailer(cfg config.MailConfig) {
+	if cfg.Host == "" {
+		slog.Warn("SMTP_HOST is not set, emails will be written to the log")
+		mailConfig = nil
+		return
+	}
+
+	mailConfig = &cfg
+
+	slog.Info("Mailer initialized", "host", cfg.Host, "port", cfg.Port)
+}
+
+// Send sends a plain text email
+func Send(to, subject, body string) error {
+	if mailConfig == nil {
+		// The body holds links and names, so it is only written at debug level
+		slog.Info("Email not sent, SMTP is not configured", "email", to, "subject", subject)
+		slog.Debug("Email body", "email", to, "body", body)
+		return nil
+	}
+
+	var authentication smtp.Auth
+	if mailConfig.Username != "" {
+		authentication = smtp.PlainAuth("", mailConfig.Username, mailConfig.Password, mailConfig.Host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + mailConfig.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"UTF-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	addr := fmt.Sprintf("%s:%s", mailConfig.Host, mailConfig.Port)
+	return smtp.SendMail(addr, authentication, mailConfig.From, []string{to}, []byte(message))
+}
+
+// SendApplicationMagicLink emails a candidate the link to track their applications
+func SendApplicationMagicLink(to, name, link string) error {
+	subject := "Lacak lamaran Anda - Job Portal"
+	body := fmt.Sprintf(`Halo %s,
+
+Terima kasih telah melamar melalui Job Portal.
+
+Gunakan tautan berikut untuk melihat status lamaran, menarik lamaran, atau mengganti CV Anda:
+
+%s
+
+Tautan ini bersifat pribadi, jangan bagikan kepada orang lain.
+
+Salam,
+Tim Job Portal`, name, link)
+
+	return Send(to, subject, body)
+}
+
+// SendAsync runs send in the background, logging its error. Shutdown waits for
+// it through Wait.
+func SendAsync(send func() error) {
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		if err := send(); err != nil {
+			slog.Error("Error sending email", "error", err)
+		}
+	}()
+}
+
+// Wait blocks until background emails are sent or ctx is done
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
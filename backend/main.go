@@ -18,23 +18,35 @@
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey AdminKey
+// @in header
+// @name X-Admin-Key
+// @description Admin API key for the /admin endpoints.
+
 package main
 
 import (
+	"context"
 	"flag"
+	"job-portal-backend/auth"
 	"job-portal-backend/database"
 	"job-portal-backend/handlers"
+	"job-portal-backend/i18n"
+	"job-portal-backend/logger"
+	"job-portal-backend/mailer"
 	"job-portal-backend/middleware"
 	"job-portal-backend/models"
+	"job-portal-backend/tracing"
 	"log"
-	"os"
+	"log/slog"
+	"time"
 
 	_ "job-portal-backend/docs"
 
 	"job-portal-backend/cache"
+	"job-portal-backend/config"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -388,11 +400,12 @@ func seedData() {
 			SalaryMax: 2000000,
 		},
 		{
-			Position:  "Intern Developer",
-			Company:   "Internship Program",
-			Location:  "Surabaya",
-			SalaryMin: 800000,
-			SalaryMax: 1500000,
+			Position:       "Intern Developer",
+			Company:        "Internship Program",
+			EmploymentType: models.EmploymentInternship,
+			Location:       "Surabaya",
+			SalaryMin:      800000,
+			SalaryMax:      1500000,
 		},
 
 		// Senior & Lead Positions
@@ -451,7 +464,7 @@ func seedData() {
 
 	// Insert sample jobs
 	for _, job := range sampleJobs {
-		err := models.CreateJob(&job)
+		err := models.CreateJob(context.Background(), &job)
 		if err != nil {
 			log.Printf("Error creating job %s: %v", job.Position, err)
 		} else {
@@ -463,23 +476,53 @@ func seedData() {
 }
 
 func main() {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file:", err)
-	}
-
 	// Parse command line flags
 	seedFlag := flag.Bool("seed", false, "Seed the database with sample data")
+	configFlag := flag.String("config", "", "Path to a YAML config file (default $CONFIG_FILE)")
 	flag.Parse()
 
+	// Load configuration from the environment, optional .env and YAML file
+	cfg, err := config.Load(*configFlag)
+
+	// Configure structured logging before anything else logs
+	logger.Init(cfg.Log)
+	if err != nil {
+		logger.Fatal("Invalid configuration", "error", err)
+	}
+
+	// Configure trace export; pending spans are flushed on exit
+	shutdownTracing := tracing.Init()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// Initialize database
-	database.InitDB()
+	database.InitDB(cfg.Database)
 
 	// Create database indexes for performance
 	database.CreateIndexes()
 
-	// Initialize Redis cache
-	cache.InitRedis()
+	// Initialize Redis and the cache store (local when Redis is unavailable)
+	cache.InitRedis(cfg.Redis)
+	cache.InitCache(cfg.Cache)
+
+	// Initialize rate limiting (shared through Redis when available)
+	middleware.InitRateLimiter(cfg.RateLimit)
+	middleware.InitRateLimitPolicies(cfg.RateLimit, cfg.Server.TrustedProxies)
+
+	// Load the default locale and format for API error messages
+	i18n.InitLocale(cfg.DefaultLocale)
+	middleware.InitProblemFormat(cfg.Errors)
+
+	// Initialize magic link signing, email delivery and handler settings
+	auth.InitMagicLink(cfg.MagicLinkSecret)
+	mailer.InitMailer(cfg.Mail)
+	handlers.InitHandlers(cfg)
+	middleware.InitAdmin(cfg.AdminAPIKey)
 
 	// Seed data if flag is provided
 	if *seedFlag {
@@ -493,13 +536,32 @@ func main() {
 	// Create router with security middleware
 	r := gin.New()
 
+	// Only trust X-Forwarded-For from known proxies when configured
+	if len(cfg.Server.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			logger.Fatal("Invalid TRUSTED_PROXIES", "error", err)
+		}
+	}
+
+	// CV uploads get a larger body limit than JSON and form requests
+	uploadLimit := int64(cfg.Security.MaxUploadBytes)
+	bodyLimits := map[string]int64{
+		"/api/applications":                  uploadLimit,
+		"/api/candidate/applications/:id/cv": uploadLimit,
+	}
+
 	// Add security and stability middleware
-	r.Use(middleware.ErrorHandler())   // Panic recovery
-	r.Use(middleware.RequestID())      // Request ID tracking
-	r.Use(middleware.RequestLogger())  // Structured logging
-	r.Use(middleware.SanitizeInput())  // Input sanitization
-	r.Use(middleware.CORSMiddleware()) // CORS
-	r.Use(middleware.GeneralRateLimit) // General rate limiting
+	r.Use(middleware.Metrics())                                               // Request metrics
+	r.Use(middleware.Tracing())                                               // Distributed tracing
+	r.Use(middleware.ErrorHandler())                                          // Panic recovery
+	r.Use(middleware.SecurityHeaders(cfg.Security))                           // Security headers
+	r.Use(middleware.RequestID())                                             // Request ID tracking
+	r.Use(middleware.RequestLogger())                                         // Structured logging
+	r.Use(middleware.CORSMiddleware(cfg.CORS))                                // CORS, which replaces Vary
+	r.Use(middleware.Localization())                                          // Locale negotiation
+	r.Use(middleware.BodyLimit(int64(cfg.Security.MaxBodyBytes), bodyLimits)) // Request body limits
+	r.Use(middleware.RateLimit("general"))                                    // General rate limiting
+	r.Use(middleware.ReadYourWrites(cfg.Database.ReadYourWritesWindow))       // Primary reads after writes
 
 	// Health check endpoints
 	r.GET("/health", handlers.HealthCheck)
@@ -511,31 +573,54 @@ func main() {
 	api := r.Group("/api")
 	{
 		// Jobs endpoints with search rate limiting
-		api.GET("/jobs", middleware.SearchRateLimit, middleware.ValidateQueryParams(), handlers.GetJobs)
+		api.GET("/jobs", middleware.RateLimit("search"), middleware.ValidateQueryParams(), handlers.GetJobs)
 		api.GET("/jobs/:id", handlers.GetJobByID)
-		api.POST("/jobs", middleware.JobCreationRateLimit, middleware.ValidateJobInput(), handlers.CreateJob)
+		api.POST("/jobs", middleware.RateLimit("job_creation"), middleware.ValidateJobInput(), handlers.CreateJob)
 		api.GET("/locations", handlers.GetLocations)
 
 		// Applications endpoints with strict rate limiting
-		api.POST("/applications", middleware.ApplicationRateLimit, middleware.ValidateApplicationInput(), handlers.CreateApplication)
-		api.GET("/applications", handlers.GetApplications)
-		api.GET("/applications/:id", handlers.GetApplicationByID)
+		api.POST("/applications", middleware.RateLimit("application"), middleware.ValidateApplicationInput(), handlers.CreateApplication)
+		api.POST("/applications/magic-link", middleware.RateLimit("application"), middleware.ValidateMagicLinkRequest(), handlers.RequestMagicLink)
+
+		// Candidate self-service endpoints authenticated by magic link
+		candidate := api.Group("/candidate", middleware.CandidateAuth())
+		{
+			candidate.GET("/applications", handlers.GetMyApplications)
+			candidate.POST("/applications/:id/withdraw", handlers.WithdrawMyApplication)
+			candidate.PUT("/applications/:id/cv", middleware.ValidateCVUpload(), handlers.ReplaceMyApplicationCV)
+		}
+
+		// Admin endpoints authenticated by the admin API key
+		admin := api.Group("/admin", middleware.AdminAuth())
+		{
+			admin.GET("/applications", handlers.GetApplications)
+			admin.GET("/applications/:id", handlers.GetApplicationByID)
+			admin.GET("/cache", handlers.GetCacheStats)
+			admin.GET("/cache/keys", handlers.GetCacheKeys)
+			admin.DELETE("/cache/namespaces/:namespace", handlers.EvictCacheNamespace)
+			admin.POST("/cache/warm", handlers.WarmUpCache)
+		}
 	}
 
-	// Serve static files from uploads directory
-	r.Static("/uploads", "./uploads")
+	// Unknown routes get the same problem+json error shape
+	r.NoRoute(middleware.NoRoute())
 
-	// Swagger documentation
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Serve uploaded files as downloads from the uploads directory
+	uploads := r.Group("/uploads", middleware.AttachmentHeaders())
+	uploads.Static("/", "./uploads")
 
-	// Get port from .env file
-	port := os.Getenv("PORT")
-	if port == "" {
-		log.Fatal("PORT environment variable is required in .env file")
-	}
+	// Swagger documentation with a CSP allowing only its own scripts
+	swaggerHandler := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	r.GET("/swagger/*any", middleware.SwaggerCSP(swaggerHandler), swaggerHandler)
 
-	log.Printf("Server starting on port %s...", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Warm up the cache in the background so startup is not delayed
+	if cfg.Cache.WarmUp {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			handlers.WarmCache(ctx)
+		}()
 	}
+
+	runServer(r, cfg.Server)
 }
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"job-portal-backend/i18n"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyHeader carries the admin API key
+const AdminKeyHeader = "X-Admin-Key"
+
+// adminAPIKey authenticates the admin endpoints; empty disables them
+var adminAPIKey string
+
+// InitAdmin sets the admin API key
+func InitAdmin(key string) {
+	adminAPIKey = key
+	if key == "" {
+		slog.Warn("ADMIN_API_KEY not set, admin endpoints are disabled")
+	}
+}
+
+// AdminAuth middleware authenticates operators by the admin API key. Every
+// request is rejected while no key is configured.
+func AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(AdminKeyHeader)
+		if key == "" {
+			CustomError(c, http.StatusUnauthorized, "Unauthorized", i18n.ErrAdminKeyRequired)
+			return
+		}
+
+		if adminAPIKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(adminAPIKey)) != 1 {
+			CustomError(c, http.StatusUnauthorized, "Unauthorized", i18n.ErrAdminKeyInvalid)
+			return
+		}
+
+		withLogger(c, "user", "admin")
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"job-portal-backend/auth"
+	"job-portal-backend/i18n"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CandidateAuth middleware authenticates candidates with a magic link token
+func CandidateAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := candidateToken(c)
+		if token == "" {
+			CustomError(c, http.StatusUnauthorized, "Unauthorized", i18n.ErrMagicLinkRequired)
+			c.Abort()
+			return
+		}
+
+		claims, err := auth.VerifyMagicLinkToken(token)
+		if err != nil {
+			code := i18n.ErrMagicLinkInvalid
+			if errors.Is(err, auth.ErrExpiredToken) {
+				code = i18n.ErrMagicLinkExpired
+			}
+			CustomError(c, http.StatusUnauthorized, "Unauthorized", code)
+			c.Abort()
+			return
+		}
+
+		c.Set("candidate_email", claims.Email)
+		withLogger(c, "user", claims.Email)
+		c.Next()
+	}
+}
+
+// candidateToken returns the magic link token from the Authorization header or the link itself
+func candidateToken(c *gin.Context) string {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		token = c.Query("token")
+	}
+	return token
+}
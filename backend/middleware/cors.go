@@ -1,21 +1,184 @@
 package middleware
 
 import (
+	"job-portal-backend/config"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{
-		"http://localhost:5173",
-		"http://127.0.0.1:5173",
-		"https://job-portal-frontend.vercel.app", // Vercel deployment
-		"https://*.vercel.app",                   // Any Vercel subdomain
+// originPattern matches an origin exactly, by a wildcard first label, or any origin
+type originPattern struct {
+	any    bool
+	scheme string
+	port   string
+
+	// host is the exact host, or the parent domain with a leading dot for
+	// wildcard patterns
+	host     string
+	wildcard bool
+
+	// labelPrefix is the start of the first label of prefix* patterns
+	labelPrefix string
+}
+
+// wildcardLabel matches the wildcard first label of an origin pattern, * or
+// a prefix followed by *
+var wildcardLabel = regexp.MustCompile(`^([a-z]+://)([a-z0-9-]*)\*\.`)
+
+// parseOriginPattern parses *, https://example.com, https://*.example.com or
+// https://prefix-*.example.com. Patterns are validated by the config package
+// at startup.
+func parseOriginPattern(value string) (originPattern, bool) {
+	if value == "*" {
+		return originPattern{any: true}, true
+	}
+
+	value = strings.ToLower(value)
+	var pattern originPattern
+	if groups := wildcardLabel.FindStringSubmatch(value); groups != nil {
+		pattern.wildcard = true
+		pattern.labelPrefix = groups[2]
+		value = groups[1] + value[len(groups[0]):]
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return originPattern{}, false
+	}
+
+	pattern.scheme = u.Scheme
+	pattern.host = u.Hostname()
+	pattern.port = u.Port()
+	if pattern.wildcard {
+		pattern.host = "." + pattern.host
+	}
+	return pattern, true
+}
+
+// matches reports whether the origin is allowed by the pattern. A * pattern
+// matches subdomains at any depth but not the parent domain itself, so
+// https://*.example.com allows https://preview-123.example.com only. A prefix*
+// pattern matches one label starting with the prefix, so
+// https://app-*.vercel.app allows https://app-git-main.vercel.app but not
+// https://evil.app-x.vercel.app.
+func (p originPattern) matches(origin *url.URL) bool {
+	if p.any {
+		return true
+	}
+	if !strings.EqualFold(origin.Scheme, p.scheme) || origin.Port() != p.port {
+		return false
+	}
+
+	host := strings.ToLower(origin.Hostname())
+	if !p.wildcard {
+		return host == p.host
+	}
+	if len(host) <= len(p.host) || !strings.HasSuffix(host, p.host) {
+		return false
+	}
+	if p.labelPrefix == "" {
+		return true
+	}
+
+	label := strings.TrimSuffix(host, p.host)
+	return !strings.Contains(label, ".") && len(label) > len(p.labelPrefix) && strings.HasPrefix(label, p.labelPrefix)
+}
+
+// originMatcher reports whether an Origin header is allowed by any of its patterns
+type originMatcher []originPattern
+
+func newOriginMatcher(origins []string) originMatcher {
+	matcher := make(originMatcher, 0, len(origins))
+	for _, origin := range origins {
+		if pattern, ok := parseOriginPattern(strings.TrimSuffix(origin, "/")); ok {
+			matcher = append(matcher, pattern)
+		}
+	}
+	return matcher
+}
+
+func (m originMatcher) allow(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return false
+	}
+
+	for _, pattern := range m {
+		if pattern.matches(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsTenant is the CORS handler used for requests to the hosts of a tenant
+type corsTenant struct {
+	hosts   []string
+	handler gin.HandlerFunc
+}
+
+// matchesHost reports whether host, without its port, is one of the tenant
+// hosts. Hosts may start with a *. wildcard label.
+func (t corsTenant) matchesHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
 	}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	config.AllowCredentials = true
+	host = strings.ToLower(host)
+
+	for _, pattern := range t.hosts {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if len(host) > len(suffix) && strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware applies the CORS policy of cfg. Requests to the hosts of a
+// tenant also accept the tenant's origins.
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	defaultHandler := newCORSHandler(cfg, cfg.AllowedOrigins)
+
+	tenants := make([]corsTenant, 0, len(cfg.Tenants))
+	for _, tenant := range cfg.Tenants {
+		origins := append(append([]string{}, cfg.AllowedOrigins...), tenant.Origins...)
+		tenants = append(tenants, corsTenant{
+			hosts:   tenant.Hosts,
+			handler: newCORSHandler(cfg, origins),
+		})
+	}
+
+	return func(c *gin.Context) {
+		for _, tenant := range tenants {
+			if tenant.matchesHost(c.Request.Host) {
+				tenant.handler(c)
+				return
+			}
+		}
+		defaultHandler(c)
+	}
+}
+
+// newCORSHandler creates a gin-contrib/cors handler allowing origins
+func newCORSHandler(cfg config.CORSConfig, origins []string) gin.HandlerFunc {
+	matcher := newOriginMatcher(origins)
+
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOriginFunc = matcher.allow
+	corsConfig.AllowMethods = cfg.AllowedMethods
+	corsConfig.AllowHeaders = cfg.AllowedHeaders
+	corsConfig.ExposeHeaders = cfg.ExposedHeaders
+	corsConfig.AllowCredentials = cfg.AllowCredentials
+	corsConfig.MaxAge = cfg.MaxAge
 
-	return cors.New(config)
+	return cors.New(corsConfig)
 }
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestOriginPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		// Any origin
+		{"*", "https://anything.example", true},
+
+		// Exact origins
+		{"https://job-portal-frontend.vercel.app", "https://job-portal-frontend.vercel.app", true},
+		{"https://job-portal-frontend.vercel.app", "https://JOB-PORTAL-FRONTEND.vercel.app", true},
+		{"https://job-portal-frontend.vercel.app", "http://job-portal-frontend.vercel.app", false},
+		{"https://job-portal-frontend.vercel.app", "https://job-portal-frontend.vercel.app.evil.com", false},
+		{"https://job-portal-frontend.vercel.app", "https://x.job-portal-frontend.vercel.app", false},
+
+		// Ports
+		{"http://localhost:5173", "http://localhost:5173", true},
+		{"http://localhost:5173", "http://localhost:5174", false},
+		{"http://localhost:5173", "http://localhost", false},
+		{"https://example.com", "https://example.com:8443", false},
+		{"https://*.example.com", "https://app.example.com:8443", false},
+		{"https://*.example.com:8443", "https://app.example.com:8443", true},
+
+		// Wildcard labels match subdomains at any depth, never the parent domain
+		{"https://*.example.com", "https://preview-123.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://.example.com", false},
+		{"https://*.example.com", "https://evilexample.com", false},
+		{"https://*.example.com", "https://example.com.evil.com", false},
+		{"https://*.example.com", "http://app.example.com", false},
+
+		// Prefix labels match one label starting with the prefix
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend-git-main.vercel.app", true},
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend-abc123.vercel.app", true},
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend-.vercel.app", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend.vercel.app", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://vercel.app", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://evil.job-portal-frontend-x.vercel.app", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend-x.evil.vercel.app", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://evil-job-portal-frontend-x.vercel.app", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend-x.vercel.app.evil.com", false},
+		{"https://job-portal-frontend-*.vercel.app", "https://job-portal-frontend-x.evilvercel.app", false},
+	}
+
+	for _, tt := range tests {
+		pattern, ok := parseOriginPattern(tt.pattern)
+		if !ok {
+			t.Fatalf("parseOriginPattern(%q) failed", tt.pattern)
+		}
+		origin, err := url.Parse(tt.origin)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", tt.origin, err)
+		}
+
+		if got := pattern.matches(origin); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestOriginMatcherAllow(t *testing.T) {
+	matcher := newOriginMatcher([]string{"https://job-portal-frontend.vercel.app/", "https://job-portal-frontend-*.vercel.app"})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://job-portal-frontend.vercel.app", true},
+		{"https://job-portal-frontend-git-main.vercel.app", true},
+		{"https://job-portal-frontend.vercel.app/", true},
+		{"https://job-portal-frontend.vercel.app/path", false},
+		{"https://evil.job-portal-frontend-x.vercel.app", false},
+		{"null", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := matcher.allow(tt.origin); got != tt.want {
+			t.Errorf("allow(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
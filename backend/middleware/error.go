@@ -1,106 +1,69 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
+	"io"
+	"job-portal-backend/i18n"
+	"job-portal-backend/logger"
+	"log/slog"
 	"net/http"
 	"runtime/debug"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// ErrorResponse represents a structured error response
-type ErrorResponse struct {
-	Error      string    `json:"error"`
-	Message    string    `json:"message"`
-	Timestamp  time.Time `json:"timestamp"`
-	RequestID  string    `json:"request_id,omitempty"`
-	Path       string    `json:"path,omitempty"`
-	Method     string    `json:"method,omitempty"`
-	StatusCode int       `json:"status_code"`
-}
-
-// LogEntry represents a structured log entry
-type LogEntry struct {
-	Timestamp  time.Time     `json:"timestamp"`
-	Level      string        `json:"level"`
-	Message    string        `json:"message"`
-	RequestID  string        `json:"request_id,omitempty"`
-	ClientIP   string        `json:"client_ip"`
-	Method     string        `json:"method"`
-	Path       string        `json:"path"`
-	StatusCode int           `json:"status_code"`
-	Duration   time.Duration `json:"duration"`
-	UserAgent  string        `json:"user_agent"`
-	Error      string        `json:"error,omitempty"`
-	Stack      string        `json:"stack,omitempty"`
-}
-
 // ErrorHandler middleware handles panics and errors
 func ErrorHandler() gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		// Log the panic
-		log.Printf("PANIC: %v", recovered)
-		log.Printf("Stack: %s", debug.Stack())
-
-		// Create error response
-		errorResponse := ErrorResponse{
-			Error:      "Internal Server Error",
-			Message:    "An unexpected error occurred",
-			Timestamp:  time.Now(),
-			RequestID:  c.GetString("request_id"),
-			Path:       c.Request.URL.Path,
-			Method:     c.Request.Method,
-			StatusCode: http.StatusInternalServerError,
-		}
-
-		// Log structured error
-		logEntry := LogEntry{
-			Timestamp:  time.Now(),
-			Level:      "ERROR",
-			Message:    "Panic recovered",
-			RequestID:  c.GetString("request_id"),
-			ClientIP:   c.ClientIP(),
-			Method:     c.Request.Method,
-			Path:       c.Request.URL.Path,
-			StatusCode: http.StatusInternalServerError,
-			UserAgent:  c.Request.UserAgent(),
-			Error:      errorResponse.Error,
-			Stack:      string(debug.Stack()),
-		}
-
-		log.Printf("ERROR_LOG: %+v", logEntry)
-
-		c.JSON(http.StatusInternalServerError, errorResponse)
+	// gin's own plain text panic output is discarded, the panic is logged below
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		Logger(c).Error("Panic recovered",
+			"error", fmt.Sprint(recovered),
+			"stack", string(debug.Stack()),
+		)
+		trace.SpanFromContext(c.Request.Context()).RecordError(fmt.Errorf("panic: %v", recovered))
+
+		WriteProblem(c, NewProblem(c, http.StatusInternalServerError, "Internal Server Error", i18n.ErrInternal))
 	})
 }
 
 // RequestLogger middleware logs all requests
 func RequestLogger() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		// Create structured log entry
-		logEntry := LogEntry{
-			Timestamp:  param.TimeStamp,
-			Level:      "INFO",
-			Message:    "HTTP Request",
-			RequestID:  param.Keys["request_id"].(string),
-			ClientIP:   param.ClientIP,
-			Method:     param.Method,
-			Path:       param.Path,
-			StatusCode: param.StatusCode,
-			Duration:   param.Latency,
-			UserAgent:  param.Request.UserAgent(),
-		}
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
 
-		// Log based on status code
-		if param.StatusCode >= 400 {
-			logEntry.Level = "ERROR"
-			logEntry.Message = "HTTP Error"
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
 		}
 
-		log.Printf("REQUEST_LOG: %+v", logEntry)
-		return ""
-	})
+		Logger(c).Log(c.Request.Context(), level, "HTTP request",
+			"path", c.Request.URL.Path,
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", c.ClientIP(),
+			"user_agent", c.Request.UserAgent(),
+			"bytes", c.Writer.Size(),
+		)
+	}
+}
+
+// Logger returns the request-scoped logger, carrying the request ID, route and user
+func Logger(c *gin.Context) *slog.Logger {
+	return logger.FromContext(c.Request.Context())
+}
+
+// withLogger attaches a request-scoped logger with additional attributes
+func withLogger(c *gin.Context, args ...any) {
+	c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), Logger(c).With(args...)))
 }
 
 // RequestID middleware adds a unique request ID
@@ -112,6 +75,22 @@ func RequestID() gin.HandlerFunc {
 		}
 		c.Set("request_id", requestID)
 		c.Header("X-Request-ID", requestID)
+
+		args := []any{
+			"request_id", requestID,
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+		}
+		// Correlate log lines with the trace started by the Tracing middleware
+		if span := trace.SpanFromContext(c.Request.Context()); span.SpanContext().IsValid() {
+			span.SetAttributes(attribute.String("request.id", requestID))
+			args = append(args,
+				"trace_id", span.SpanContext().TraceID().String(),
+				"span_id", span.SpanContext().SpanID().String(),
+			)
+		}
+		withLogger(c, args...)
+
 		c.Next()
 	}
 }
@@ -131,70 +110,49 @@ func randomString(length int) string {
 	return string(b)
 }
 
-// CustomError creates a custom error response
-func CustomError(c *gin.Context, statusCode int, errorType, message string) {
-	errorResponse := ErrorResponse{
-		Error:      errorType,
-		Message:    message,
-		Timestamp:  time.Now(),
-		RequestID:  c.GetString("request_id"),
-		Path:       c.Request.URL.Path,
-		Method:     c.Request.Method,
-		StatusCode: statusCode,
-	}
+// CustomError creates a problem response with a detail localized from code
+func CustomError(c *gin.Context, statusCode int, errorType, code string, args ...interface{}) {
+	problem := NewProblem(c, statusCode, errorType, code, args...)
 
-	// Log the error
-	logEntry := LogEntry{
-		Timestamp:  time.Now(),
-		Level:      "ERROR",
-		Message:    "Custom Error",
-		RequestID:  c.GetString("request_id"),
-		ClientIP:   c.ClientIP(),
-		Method:     c.Request.Method,
-		Path:       c.Request.URL.Path,
-		StatusCode: statusCode,
-		UserAgent:  c.Request.UserAgent(),
-		Error:      errorType,
+	level := slog.LevelWarn
+	if statusCode >= http.StatusInternalServerError {
+		level = slog.LevelError
 	}
+	Logger(c).Log(c.Request.Context(), level, "Request failed",
+		"status", statusCode,
+		"error", errorType,
+		"code", code,
+	)
 
-	log.Printf("ERROR_LOG: %+v", logEntry)
-
-	c.JSON(statusCode, errorResponse)
+	WriteProblem(c, problem)
 }
 
-// ValidationErrorResponse creates a validation error response
-func ValidationErrorResponse(c *gin.Context, errors []ValidationError) {
-	errorResponse := ErrorResponse{
-		Error:      "Validation Error",
-		Message:    "Input validation failed",
-		Timestamp:  time.Now(),
-		RequestID:  c.GetString("request_id"),
-		Path:       c.Request.URL.Path,
-		Method:     c.Request.Method,
-		StatusCode: http.StatusBadRequest,
+// ValidationErrorResponse creates a validation problem response listing every
+// failed field. A body over the size limit is reported as 413 instead.
+func ValidationErrorResponse(c *gin.Context, code string, errors []ValidationError) {
+	for _, e := range errors {
+		if e.Code == i18n.ErrPayloadTooLarge {
+			PayloadTooLarge(c)
+			return
+		}
 	}
 
-	// Log the validation error
-	logEntry := LogEntry{
-		Timestamp:  time.Now(),
-		Level:      "WARN",
-		Message:    "Validation Error",
-		RequestID:  c.GetString("request_id"),
-		ClientIP:   c.ClientIP(),
-		Method:     c.Request.Method,
-		Path:       c.Request.URL.Path,
-		StatusCode: http.StatusBadRequest,
-		UserAgent:  c.Request.UserAgent(),
-		Error:      "Validation failed",
+	problem := NewProblem(c, http.StatusBadRequest, "Validation Error", code)
+	problem.Errors = errors
+
+	// Only field names and codes are logged, never the submitted values
+	fields := make([]string, 0, len(errors))
+	for _, e := range errors {
+		fields = append(fields, e.Field+":"+e.Code)
 	}
+	Logger(c).Warn("Validation failed", "code", code, "fields", fields)
 
-	log.Printf("VALIDATION_LOG: %+v", logEntry)
+	WriteProblem(c, problem)
+}
 
-	c.JSON(http.StatusBadRequest, gin.H{
-		"error":      errorResponse.Error,
-		"message":    errorResponse.Message,
-		"details":    errors,
-		"timestamp":  errorResponse.Timestamp,
-		"request_id": errorResponse.RequestID,
-	})
+// NoRoute responds to unknown routes with a not found problem
+func NoRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		WriteProblem(c, NewProblem(c, http.StatusNotFound, "Not Found", i18n.ErrRouteNotFound))
+	}
 }
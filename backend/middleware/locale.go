@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"job-portal-backend/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Localization middleware negotiates the response locale from Accept-Language.
+// It must run after CORSMiddleware, which overwrites the Vary header.
+func Localization() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set("locale", locale)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// GetLocale returns the negotiated locale of the request
+func GetLocale(c *gin.Context) string {
+	if locale := c.GetString("locale"); locale != "" {
+		return locale
+	}
+	return i18n.DefaultLocale()
+}
+
+// Localize returns the message for code in the request locale
+func Localize(c *gin.Context, code string, args ...interface{}) string {
+	return i18n.T(GetLocale(c), code, args...)
+}
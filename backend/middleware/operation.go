@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"job-portal-backend/database"
+	"job-portal-backend/i18n"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// statusClientClosedRequest is logged when the client went away before the
+// response was written, following the nginx convention
+const statusClientClosedRequest = 499
+
+// unavailableRetryAfter is suggested to clients when a backend is unreachable
+const unavailableRetryAfter = 5 * time.Second
+
+// OperationError responds to a failed database or cache operation. An
+// operation that ran past its deadline gives 504 and an unreachable backend
+// 503; any other error gives the 500 problem of code. Nothing is written
+// when the client disconnected, since the operation was canceled for it.
+func OperationError(c *gin.Context, err error, errorType, code string, args ...interface{}) {
+	switch {
+	case c.Request.Context().Err() != nil:
+		Logger(c).Info("Request canceled by client", "error", err)
+		c.AbortWithStatus(statusClientClosedRequest)
+	case isTimeout(err):
+		CustomError(c, http.StatusGatewayTimeout, "Gateway Timeout", i18n.ErrOperationTimeout)
+	case database.IsUnavailable(err):
+		c.Header("Retry-After", strconv.Itoa(int(unavailableRetryAfter.Seconds())))
+		CustomError(c, http.StatusServiceUnavailable, "Service Unavailable", i18n.ErrServiceUnavailable)
+	default:
+		CustomError(c, http.StatusInternalServerError, errorType, code, args...)
+	}
+}
+
+// isTimeout reports whether err comes from an operation deadline. PostgreSQL
+// reports a statement canceled at its deadline as query_canceled.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "query_canceled"
+}
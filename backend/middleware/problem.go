@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"encoding/json"
+	"job-portal-backend/config"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses
+const ProblemContentType = "application/problem+json"
+
+// Error response formats
+const (
+	// ErrorFormatCompat adds the members of the previous error shape
+	// (error, message, details, status_code) to every problem document
+	ErrorFormatCompat = "compat"
+
+	// ErrorFormatProblem emits plain RFC 7807 documents
+	ErrorFormatProblem = "problem"
+)
+
+// defaultProblemTypeBase prefixes the error code to build the problem type URI
+const defaultProblemTypeBase = "urn:job-portal:problem:"
+
+var (
+	errorFormat     = ErrorFormatCompat
+	problemTypeBase = defaultProblemTypeBase
+)
+
+// Problem represents an RFC 7807 problem details document
+// @Description RFC 7807 problem details, with compatibility members unless ERROR_FORMAT=problem
+type Problem struct {
+	Type      string            `json:"type" example:"urn:job-portal:problem:job-not-found"`
+	Title     string            `json:"title" example:"Not Found"`
+	Status    int               `json:"status" example:"404"`
+	Detail    string            `json:"detail,omitempty" example:"Lowongan tidak ditemukan"`
+	Instance  string            `json:"instance,omitempty" example:"/api/jobs/42"`
+	Code      string            `json:"code" example:"JOB_NOT_FOUND"`
+	RequestID string            `json:"request_id,omitempty" example:"20250115103000-abcd1234"`
+	Timestamp time.Time         `json:"timestamp" example:"2025-01-15T10:30:00Z"`
+	Errors    []ValidationError `json:"errors,omitempty"`
+
+	// Compatibility members for clients written against the previous error shape
+	Error      string            `json:"error,omitempty" example:"Not Found"`
+	Message    string            `json:"message,omitempty" example:"Lowongan tidak ditemukan"`
+	Details    []ValidationError `json:"details,omitempty"`
+	StatusCode int               `json:"status_code,omitempty" example:"404"`
+	RetryAfter int               `json:"retry_after,omitempty"`
+}
+
+// InitProblemFormat sets the error response format and problem type base URI
+func InitProblemFormat(cfg config.ErrorsConfig) {
+	switch strings.ToLower(cfg.Format) {
+	case ErrorFormatProblem:
+		errorFormat = ErrorFormatProblem
+	default:
+		errorFormat = ErrorFormatCompat
+	}
+
+	if cfg.TypeBaseURI != "" {
+		problemTypeBase = cfg.TypeBaseURI
+	}
+}
+
+// NewProblem creates a problem document for the request with a detail localized from code
+func NewProblem(c *gin.Context, status int, title, code string, args ...interface{}) *Problem {
+	return &Problem{
+		Type:      ProblemType(code),
+		Title:     title,
+		Status:    status,
+		Detail:    Localize(c, code, args...),
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now(),
+	}
+}
+
+// ProblemType returns the type URI of an error code
+func ProblemType(code string) string {
+	return problemTypeBase + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// WriteProblem writes the problem as application/problem+json and aborts the request
+func WriteProblem(c *gin.Context, problem *Problem) {
+	if wantsCompat(c) {
+		problem.Error = problem.Title
+		problem.Message = problem.Detail
+		problem.Details = problem.Errors
+		problem.StatusCode = problem.Status
+	}
+
+	c.Render(problem.Status, problemRender{problem: problem})
+	c.Abort()
+}
+
+// wantsCompat reports whether compatibility members should be added. Clients
+// that explicitly ask for application/problem+json always get the plain format.
+func wantsCompat(c *gin.Context) bool {
+	if errorFormat != ErrorFormatCompat {
+		return false
+	}
+	return !strings.Contains(c.GetHeader("Accept"), ProblemContentType)
+}
+
+// problemRender renders a problem with the problem+json content type
+type problemRender struct {
+	problem *Problem
+}
+
+// Render writes the problem as JSON
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	body, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(body)
+	return err
+}
+
+// WriteContentType sets the problem+json content type
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ProblemContentType)
+}
@@ -1,49 +1,124 @@
 package middleware
 
 import (
+	"context"
+	"job-portal-backend/cache"
+	"job-portal-backend/config"
+	"job-portal-backend/i18n"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RateLimiter represents a rate limiter
-type RateLimiter struct {
-	requests map[string][]time.Time
-	mutex    sync.RWMutex
-	limit    int
-	window   time.Duration
+// Rate limiter stores
+const (
+	RateLimitStoreMemory = "memory"
+	RateLimitStoreRedis  = "redis"
+)
+
+// RateLimitResult is the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is the time until the next request slot frees up
+	Reset time.Duration
+}
+
+// Limiter decides whether a request identified by key is within limit requests per window
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error)
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		requests: make(map[string][]time.Time),
-		limit:    limit,
-		window:   window,
+var (
+	// memoryLimiter is the in-memory store, also the fallback of the Redis store
+	memoryLimiter = NewMemoryLimiter(time.Minute)
+
+	// rateLimiter is the limiter shared by every RateLimit middleware
+	rateLimiter Limiter = memoryLimiter
+)
+
+// InitRateLimiter selects the rate limiter store of cfg. Redis is used by
+// default when available so that all replicas share the same quota.
+func InitRateLimiter(cfg config.RateLimitConfig) {
+	if strings.EqualFold(cfg.Store, RateLimitStoreRedis) {
+		if client := cache.Client(); client != nil {
+			rateLimiter = NewRedisLimiter(client, rateLimiter)
+			slog.Info("Rate limiter using Redis store")
+			return
+		}
+		slog.Warn("Redis not available, rate limiter using in-memory store")
+		return
 	}
+
+	slog.Info("Rate limiter using in-memory store")
 }
 
-// RateLimit middleware limits requests per IP
-func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(limit, window)
+// StopRateLimiter stops the background cleanup of the in-memory store
+func StopRateLimiter() {
+	memoryLimiter.Stop()
+}
 
+// RateLimit middleware limits requests according to the named policy. The
+// client's tier and identity are resolved per request, see ratelimit_policy.go.
+func RateLimit(policyName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get client IP
-		clientIP := c.ClientIP()
-		if clientIP == "" {
-			clientIP = "unknown"
+		policy, ok := rateLimitPolicy(policyName)
+		if !ok {
+			Logger(c).Warn("Unknown rate limit policy", "policy", policyName)
+			c.Next()
+			return
 		}
 
-		// Check rate limit
-		if !limiter.Allow(clientIP) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Rate limit exceeded",
-				"message":     "Too many requests. Please try again later.",
-				"retry_after": int(window.Seconds()),
-			})
-			c.Abort()
+		// Internal monitoring is never limited
+		if allowlisted(c.ClientIP()) {
+			c.Next()
+			return
+		}
+
+		tier, identity := resolveClient(c, policy)
+
+		// Check rate limit. The IP limit goes first, so a client over it cannot
+		// use up the quota of the identities it sends.
+		result := RateLimitResult{Allowed: true, Remaining: math.MaxInt}
+		var err error
+		if policy.IPLimit > 0 {
+			result, err = rateLimiter.Allow(c.Request.Context(), policyName+":ip_limit:"+clientIP(c), policy.IPLimit, policy.Window)
+		}
+		if err == nil && result.Allowed {
+			var identityResult RateLimitResult
+			identityResult, err = rateLimiter.Allow(c.Request.Context(), policyName+":"+identity, policy.limit(tier), policy.Window)
+			if !identityResult.Allowed || identityResult.Remaining <= result.Remaining {
+				result = identityResult
+			}
+		}
+		if err != nil {
+			// Fail open rather than rejecting every request when the store is down
+			Logger(c).Error("Rate limiter failed", "policy", policyName, "error", err)
+			c.Next()
+			return
+		}
+
+		reset := ceilSeconds(result.Reset)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !result.Allowed {
+			rateLimitRejections.WithLabelValues(policyName).Inc()
+
+			c.Header("Retry-After", strconv.Itoa(reset))
+
+			problem := NewProblem(c, http.StatusTooManyRequests, "Rate limit exceeded", i18n.ErrRateLimitExceeded)
+			problem.RetryAfter = reset
+			WriteProblem(c, problem)
 			return
 		}
 
@@ -51,80 +126,116 @@ func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
 	}
 }
 
+// ceilSeconds rounds a duration up to whole seconds, with a minimum of one
+func ceilSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// MemoryLimiter is a sliding window limiter kept in process memory. Each
+// replica counts separately, so it is only suitable for a single instance.
+type MemoryLimiter struct {
+	requests map[string]*window
+	mutex    sync.Mutex
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// window holds the request times of a key within its window
+type window struct {
+	times  []time.Time
+	length time.Duration
+}
+
+// NewMemoryLimiter creates an in-memory limiter that drops expired keys every cleanupInterval
+func NewMemoryLimiter(cleanupInterval time.Duration) *MemoryLimiter {
+	ml := &MemoryLimiter{
+		requests: make(map[string]*window),
+		stop:     make(chan struct{}),
+	}
+	ml.StartCleanup(cleanupInterval)
+	return ml
+}
+
 // Allow checks if a request is allowed
-func (rl *RateLimiter) Allow(key string) bool {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
+func (ml *MemoryLimiter) Allow(ctx context.Context, key string, limit int, length time.Duration) (RateLimitResult, error) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
 
 	now := time.Now()
-	windowStart := now.Add(-rl.window)
-
-	// Clean old requests
-	if requests, exists := rl.requests[key]; exists {
-		var validRequests []time.Time
-		for _, reqTime := range requests {
-			if reqTime.After(windowStart) {
-				validRequests = append(validRequests, reqTime)
-			}
-		}
-		rl.requests[key] = validRequests
+
+	w, exists := ml.requests[key]
+	if !exists {
+		w = &window{length: length}
+		ml.requests[key] = w
+	}
+	w.length = length
+	w.prune(now)
+
+	result := RateLimitResult{Limit: limit}
+
+	// Add current request if the limit is not exceeded
+	if len(w.times) < limit {
+		w.times = append(w.times, now)
+		result.Allowed = true
 	}
 
-	// Check if limit exceeded
-	if len(rl.requests[key]) >= rl.limit {
-		return false
+	result.Remaining = limit - len(w.times)
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if len(w.times) > 0 {
+		result.Reset = w.times[0].Add(length).Sub(now)
 	}
 
-	// Add current request
-	rl.requests[key] = append(rl.requests[key], now)
-	return true
+	return result, nil
 }
 
-// Cleanup removes old entries to prevent memory leaks
-func (rl *RateLimiter) Cleanup() {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
+// prune drops request times that fell out of the window
+func (w *window) prune(now time.Time) {
+	windowStart := now.Add(-w.length)
 
-	now := time.Now()
-	windowStart := now.Add(-rl.window)
+	i := 0
+	for i < len(w.times) && !w.times[i].After(windowStart) {
+		i++
+	}
+	w.times = w.times[i:]
+}
 
-	for key, requests := range rl.requests {
-		var validRequests []time.Time
-		for _, reqTime := range requests {
-			if reqTime.After(windowStart) {
-				validRequests = append(validRequests, reqTime)
-			}
-		}
+// Cleanup removes expired keys to prevent memory leaks
+func (ml *MemoryLimiter) Cleanup() {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
 
-		if len(validRequests) == 0 {
-			delete(rl.requests, key)
-		} else {
-			rl.requests[key] = validRequests
+	now := time.Now()
+	for key, w := range ml.requests {
+		w.prune(now)
+		if len(w.times) == 0 {
+			delete(ml.requests, key)
 		}
 	}
 }
 
-// StartCleanup starts periodic cleanup
-func (rl *RateLimiter) StartCleanup() {
-	ticker := time.NewTicker(rl.window)
+// StartCleanup starts periodic cleanup until Stop is called
+func (ml *MemoryLimiter) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
 	go func() {
-		for range ticker.C {
-			rl.Cleanup()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ml.Cleanup()
+			case <-ml.stop:
+				return
+			}
 		}
 	}()
 }
 
-// Different rate limit configurations
-var (
-	// General API rate limit: 100 requests per minute
-	GeneralRateLimit = RateLimit(100, time.Minute)
-
-	// Application submission rate limit: 5 requests per minute
-	ApplicationRateLimit = RateLimit(5, time.Minute)
-
-	// Job creation rate limit: 10 requests per minute
-	JobCreationRateLimit = RateLimit(10, time.Minute)
-
-	// Search rate limit: 200 requests per minute
-	SearchRateLimit = RateLimit(200, time.Minute)
-)
+// Stop ends the periodic cleanup
+func (ml *MemoryLimiter) Stop() {
+	ml.stopOnce.Do(func() { close(ml.stop) })
+}
@@ -0,0 +1,405 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"job-portal-backend/auth"
+	"job-portal-backend/config"
+	"job-portal-backend/logger"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// Client tiers
+const (
+	TierAnonymous = "anonymous"
+	TierCandidate = "candidate"
+	TierRecruiter = "recruiter"
+	TierPartner   = "partner"
+)
+
+// Identities a policy can count requests by
+const (
+	KeyByIP     = "ip"
+	KeyByUser   = "user"
+	KeyByAPIKey = "api_key"
+	KeyByEmail  = "email"
+)
+
+// APIKeyHeader carries the API keys of recruiters and partners
+const APIKeyHeader = "X-API-Key"
+
+// maxEmailPeekSize bounds how much of a JSON body is read to find the email
+const maxEmailPeekSize = 64 << 10
+
+// RateLimitPolicy describes how one group of routes is limited
+type RateLimitPolicy struct {
+	// KeyBy lists the identities to count by; the first one the request has is used
+	KeyBy []string `yaml:"key_by"`
+
+	// Window is the length of the sliding window
+	Window time.Duration `yaml:"window"`
+
+	// Burst is the number of extra requests allowed on top of the tier limit
+	Burst int `yaml:"burst"`
+
+	// Tiers maps each tier to its requests per window; anonymous is required
+	Tiers map[string]int `yaml:"tiers"`
+
+	// IPLimit, when set, also limits the requests per window of each client
+	// IP. Both limits must pass, so clients cannot escape the IP limit by
+	// changing an identity they choose themselves, such as the email.
+	IPLimit int `yaml:"ip_limit"`
+}
+
+// RateLimitAPIKey assigns a tier to an API key
+type RateLimitAPIKey struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	Tier string `yaml:"tier"`
+}
+
+// RateLimitConfig is the rate limit configuration file
+type RateLimitConfig struct {
+	Policies  map[string]RateLimitPolicy `yaml:"policies"`
+	APIKeys   []RateLimitAPIKey          `yaml:"api_keys"`
+	Allowlist []string                   `yaml:"allowlist"`
+}
+
+// defaultRateLimitPolicies are used for policies missing from the configuration
+var defaultRateLimitPolicies = map[string]RateLimitPolicy{
+	// General API rate limit
+	"general": {
+		KeyBy:  []string{KeyByAPIKey, KeyByUser, KeyByIP},
+		Window: time.Minute,
+		Burst:  20,
+		Tiers:  map[string]int{TierAnonymous: 100, TierCandidate: 200, TierRecruiter: 500, TierPartner: 2000},
+	},
+
+	// Job search rate limit
+	"search": {
+		KeyBy:  []string{KeyByAPIKey, KeyByUser, KeyByIP},
+		Window: time.Minute,
+		Tiers:  map[string]int{TierAnonymous: 200, TierCandidate: 300, TierRecruiter: 1000, TierPartner: 5000},
+	},
+
+	// Job creation rate limit
+	"job_creation": {
+		KeyBy:  []string{KeyByAPIKey, KeyByIP},
+		Window: time.Minute,
+		Tiers:  map[string]int{TierAnonymous: 10, TierRecruiter: 60, TierPartner: 300},
+	},
+
+	// Application submission and magic link rate limit, per applicant email so
+	// candidates sharing an office network do not block each other, and per IP
+	// so that one client cannot send unlimited emails
+	"application": {
+		KeyBy:   []string{KeyByEmail, KeyByIP},
+		Window:  time.Minute,
+		Tiers:   map[string]int{TierAnonymous: 5, TierCandidate: 5},
+		IPLimit: 20,
+	},
+}
+
+var (
+	rateLimitMutex    sync.RWMutex
+	rateLimitPolicies = defaultRateLimitPolicies
+	rateLimitAPIKeys  []RateLimitAPIKey
+	rateLimitNets     []*net.IPNet
+)
+
+// InitRateLimitPolicies loads rate limit policies from the YAML or JSON policy
+// file of cfg. Environment variables in the file are expanded, so API keys can
+// be written as ${PARTNER_API_KEY}.
+func InitRateLimitPolicies(cfg config.RateLimitConfig, trustedProxies []string) {
+	path := cfg.PolicyFile
+	if path == "" {
+		slog.Info("Rate limit policies using defaults")
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Fatal("Error reading rate limit config", "path", path, "error", err)
+	}
+
+	var policies RateLimitConfig
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &policies); err != nil {
+		logger.Fatal("Error parsing rate limit config", "path", path, "error", err)
+	}
+
+	if err := ApplyRateLimitConfig(policies); err != nil {
+		logger.Fatal("Invalid rate limit config", "path", path, "error", err)
+	}
+
+	if len(policies.Allowlist) > 0 && len(trustedProxies) == 0 {
+		slog.Warn("Rate limit allowlist is set without TRUSTED_PROXIES, client IPs from X-Forwarded-For are trusted")
+	}
+
+	slog.Info("Rate limit policies loaded", "path", path)
+}
+
+// ApplyRateLimitConfig validates settings and replaces the active policies.
+// Policies it does not define keep their defaults.
+func ApplyRateLimitConfig(settings RateLimitConfig) error {
+	policies := make(map[string]RateLimitPolicy, len(defaultRateLimitPolicies))
+	for name, policy := range defaultRateLimitPolicies {
+		policies[name] = policy
+	}
+
+	for name, policy := range settings.Policies {
+		if len(policy.KeyBy) == 0 {
+			policy.KeyBy = []string{KeyByIP}
+		}
+		if policy.Window == 0 {
+			policy.Window = time.Minute
+		}
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("policy %s: %w", name, err)
+		}
+		policies[name] = policy
+	}
+
+	for _, key := range settings.APIKeys {
+		if key.Key == "" {
+			return fmt.Errorf("api key %s: key is empty", key.Name)
+		}
+		if !validTier(key.Tier) {
+			return fmt.Errorf("api key %s: unknown tier %q", key.Name, key.Tier)
+		}
+	}
+
+	nets := make([]*net.IPNet, 0, len(settings.Allowlist))
+	for _, entry := range settings.Allowlist {
+		ipNet, err := parseAllowlistEntry(entry)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, ipNet)
+	}
+
+	rateLimitMutex.Lock()
+	defer rateLimitMutex.Unlock()
+
+	rateLimitPolicies = policies
+	rateLimitAPIKeys = settings.APIKeys
+	rateLimitNets = nets
+	return nil
+}
+
+// validate checks the identities, tiers and limits of a policy
+func (p RateLimitPolicy) validate() error {
+	for _, keyBy := range p.KeyBy {
+		switch keyBy {
+		case KeyByIP, KeyByUser, KeyByAPIKey, KeyByEmail:
+		default:
+			return fmt.Errorf("unknown key_by %q", keyBy)
+		}
+	}
+
+	if p.Window < 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	if p.Burst < 0 {
+		return fmt.Errorf("burst must not be negative")
+	}
+	if p.IPLimit < 0 {
+		return fmt.Errorf("ip_limit must not be negative")
+	}
+	if _, ok := p.Tiers[TierAnonymous]; !ok {
+		return fmt.Errorf("tier %s is required", TierAnonymous)
+	}
+
+	for tier, limit := range p.Tiers {
+		if !validTier(tier) {
+			return fmt.Errorf("unknown tier %q", tier)
+		}
+		if limit < 1 {
+			return fmt.Errorf("tier %s limit must be positive", tier)
+		}
+	}
+
+	return nil
+}
+
+// limit returns the requests allowed per window for tier, including the burst
+func (p RateLimitPolicy) limit(tier string) int {
+	limit, ok := p.Tiers[tier]
+	if !ok {
+		limit = p.Tiers[TierAnonymous]
+	}
+	return limit + p.Burst
+}
+
+// validTier reports whether tier is a known client tier
+func validTier(tier string) bool {
+	switch tier {
+	case TierAnonymous, TierCandidate, TierRecruiter, TierPartner:
+		return true
+	}
+	return false
+}
+
+// parseAllowlistEntry parses a CIDR or a single IP address
+func parseAllowlistEntry(entry string) (*net.IPNet, error) {
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("allowlist: invalid IP %q", entry)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(entry)
+	if err != nil {
+		return nil, fmt.Errorf("allowlist: invalid CIDR %q", entry)
+	}
+	return ipNet, nil
+}
+
+// rateLimitPolicy returns the named policy
+func rateLimitPolicy(name string) (RateLimitPolicy, bool) {
+	rateLimitMutex.RLock()
+	defer rateLimitMutex.RUnlock()
+
+	policy, ok := rateLimitPolicies[name]
+	return policy, ok
+}
+
+// allowlisted reports whether clientIP is exempt from rate limiting
+func allowlisted(clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	rateLimitMutex.RLock()
+	defer rateLimitMutex.RUnlock()
+
+	for _, ipNet := range rateLimitNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveClient returns the tier of the client and the identity its requests
+// are counted under for policy
+func resolveClient(c *gin.Context, policy RateLimitPolicy) (tier, identity string) {
+	tier = TierAnonymous
+
+	apiKey := lookupAPIKey(c.GetHeader(APIKeyHeader))
+	if apiKey != nil {
+		tier = apiKey.Tier
+	}
+
+	var candidateEmail string
+	if token := candidateToken(c); token != "" {
+		if claims, err := auth.VerifyMagicLinkToken(token); err == nil {
+			candidateEmail = strings.ToLower(claims.Email)
+			if apiKey == nil {
+				tier = TierCandidate
+			}
+		}
+	}
+
+	for _, keyBy := range policy.KeyBy {
+		switch keyBy {
+		case KeyByAPIKey:
+			if apiKey != nil {
+				return tier, "key:" + apiKey.Name
+			}
+		case KeyByUser:
+			if candidateEmail != "" {
+				return tier, "user:" + hashIdentity(candidateEmail)
+			}
+		case KeyByEmail:
+			if email := requestEmail(c); email != "" {
+				return tier, "email:" + hashIdentity(email)
+			}
+		case KeyByIP:
+			return tier, "ip:" + clientIP(c)
+		}
+	}
+
+	// Every request can at least be counted by IP
+	return tier, "ip:" + clientIP(c)
+}
+
+// lookupAPIKey returns the configured API key matching key
+func lookupAPIKey(key string) *RateLimitAPIKey {
+	if key == "" {
+		return nil
+	}
+
+	rateLimitMutex.RLock()
+	defer rateLimitMutex.RUnlock()
+
+	for i := range rateLimitAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(rateLimitAPIKeys[i].Key), []byte(key)) == 1 {
+			return &rateLimitAPIKeys[i]
+		}
+	}
+	return nil
+}
+
+// requestEmail returns the lowercased email of the request from the query,
+// form data or JSON body, leaving the body readable for the handlers
+func requestEmail(c *gin.Context) string {
+	if email := c.Query("email"); email != "" {
+		return strings.ToLower(strings.TrimSpace(email))
+	}
+
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	if c.ContentType() != gin.MIMEJSON {
+		return strings.ToLower(strings.TrimSpace(c.PostForm("email")))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEmailPeekSize))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Email))
+}
+
+// clientIP returns the client IP, or "unknown" when it cannot be determined
+func clientIP(c *gin.Context) string {
+	if ip := c.ClientIP(); ip != "" {
+		return ip
+	}
+	return "unknown"
+}
+
+// hashIdentity keeps emails out of the rate limit store
+func hashIdentity(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// slidingWindowScript atomically trims the window, counts it and records the
+// request when there is room. Redis server time is used so every replica
+// agrees on the window regardless of clock drift.
+//
+// Returns {allowed, remaining, reset in milliseconds}.
+var slidingWindowScript = redis.NewScript(`
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, now .. '-' .. member)
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = 0
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+local remaining = limit - count
+if remaining < 0 then
+	remaining = 0
+end
+
+return {allowed, remaining, reset}
+`)
+
+// redisKeyPrefix namespaces rate limit keys in Redis
+const redisKeyPrefix = "ratelimit:"
+
+// RedisLimiter is a sliding window limiter shared by every replica through Redis
+type RedisLimiter struct {
+	client   *redis.Client
+	fallback Limiter
+	sequence uint64
+}
+
+// NewRedisLimiter creates a Redis limiter. When Redis fails, requests are
+// checked against fallback instead so limits still apply per replica.
+func NewRedisLimiter(client *redis.Client, fallback Limiter) *RedisLimiter {
+	return &RedisLimiter{
+		client:   client,
+		fallback: fallback,
+	}
+}
+
+// Allow checks if a request is allowed
+func (rl *RedisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	// The member only needs to be unique among requests in the same millisecond
+	member := strconv.FormatUint(atomic.AddUint64(&rl.sequence, 1), 36) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	values, err := slidingWindowScript.Run(ctx, rl.client, []string{redisKeyPrefix + key},
+		limit, window.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		if rl.fallback == nil {
+			return RateLimitResult{}, err
+		}
+		slog.Warn("Redis rate limiter failed, using fallback", "error", err)
+		return rl.fallback.Allow(ctx, key, limit, window)
+	}
+
+	return RateLimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: int(values[1]),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
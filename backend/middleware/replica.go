@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"job-portal-backend/auth"
+	"job-portal-backend/cache"
+	"job-portal-backend/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readPrimaryCookie holds when a client's reads may go back to the replicas,
+// in Unix milliseconds, signed so clients cannot extend it
+const readPrimaryCookie = "read_primary_until"
+
+// readPrimaryPurpose scopes the signature of the cookie
+const readPrimaryPurpose = "read_primary_until"
+
+// maxClockSkew tolerates clocks of instances being slightly apart when
+// checking that a cookie is not set further ahead than the window
+const maxClockSkew = time.Second
+
+// ReadYourWrites middleware sends a client's reads to the primary database
+// for window after it made a successful write, so it sees its own changes
+// despite replication lag. The window is kept in a signed cookie, which
+// every instance honors, and cookies reaching further than window ahead are
+// ignored. Cached reads are bypassed too, since other clients may have
+// cached rows from a lagging replica. It does nothing without replicas.
+func ReadYourWrites(window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if window <= 0 || !database.HasReplicas() {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		if value, err := c.Cookie(readPrimaryCookie); err == nil && readPrimaryActive(value, now, window) {
+			ctx := database.WithPrimary(c.Request.Context())
+			c.Request = c.Request.WithContext(cache.WithoutCache(ctx))
+		}
+
+		// The cookie must be set before the response headers are written, so
+		// it is added when the handler writes a successful status
+		if !isWrite(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		writer := &readPrimaryWriter{ResponseWriter: c.Writer, c: c, window: window}
+		c.Writer = writer
+		c.Next()
+
+		// Responses without a body are only written after the handlers return
+		writer.beforeWrite()
+	}
+}
+
+// readPrimaryActive reports whether the signed cookie value keeps reads on
+// the primary at now
+func readPrimaryActive(value string, now time.Time, window time.Duration) bool {
+	value, ok := auth.VerifyValue(readPrimaryPurpose, value)
+	if !ok {
+		return false
+	}
+
+	until, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return now.UnixMilli() < until && until <= now.Add(window+maxClockSkew).UnixMilli()
+}
+
+// isWrite reports whether method may change data
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// readPrimaryWriter sets the read primary cookie when the response of a
+// write has a 2xx status
+type readPrimaryWriter struct {
+	gin.ResponseWriter
+	c       *gin.Context
+	window  time.Duration
+	checked bool
+}
+
+// beforeWrite adds the cookie once the status is final, right before the
+// headers are sent
+func (w *readPrimaryWriter) beforeWrite() {
+	if w.checked || w.ResponseWriter.Written() {
+		return
+	}
+	w.checked = true
+
+	if status := w.ResponseWriter.Status(); status >= 200 && status < 300 {
+		setReadPrimaryCookie(w.c, time.Now().Add(w.window), w.window)
+	}
+}
+
+func (w *readPrimaryWriter) WriteHeaderNow() {
+	w.beforeWrite()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *readPrimaryWriter) Write(data []byte) (int, error) {
+	w.beforeWrite()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *readPrimaryWriter) WriteString(s string) (int, error) {
+	w.beforeWrite()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// setReadPrimaryCookie keeps the client's reads on the primary until until.
+// Over HTTPS the cookie is also sent by the frontend on another site.
+func setReadPrimaryCookie(c *gin.Context, until time.Time, window time.Duration) {
+	cookie := &http.Cookie{
+		Name:     readPrimaryCookie,
+		Value:    auth.SignValue(readPrimaryPurpose, strconv.FormatInt(until.UnixMilli(), 10)),
+		Path:     "/",
+		MaxAge:   int(window.Round(time.Second).Seconds()) + 1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if isHTTPS(c) {
+		cookie.Secure = true
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(c.Writer, cookie)
+}
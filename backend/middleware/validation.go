@@ -1,25 +1,41 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"job-portal-backend/i18n"
+	"job-portal-backend/models"
+	"job-portal-backend/sanitize"
 	"net/http"
+	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
 // ValidationError represents a validation error
 type ValidationError struct {
 	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
-// ValidationResponse represents validation error response
-type ValidationResponse struct {
-	Error   string            `json:"error"`
-	Details []ValidationError `json:"details"`
-}
+// Context keys under which validated request DTOs are stored
+const (
+	JobInputKey         = "job_input"
+	JobListQueryKey     = "job_list_query"
+	ApplicationInputKey = "application_input"
+	MagicLinkInputKey   = "magic_link_input"
+)
+
+// defaultMultipartMemory matches gin's in-memory limit for multipart forms
+const defaultMultipartMemory = 32 << 20
 
 // Validation rules
 var (
@@ -31,239 +47,415 @@ var (
 	nameRegex     = regexp.MustCompile(`^[a-zA-Z\s]{2,50}$`)
 )
 
-// ValidateJobInput validates job creation/update input
-func ValidateJobInput() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var errors []ValidationError
-
-		// Validate position
-		position := c.PostForm("position")
-		if position == "" {
-			errors = append(errors, ValidationError{Field: "position", Message: "Position is required"})
-		} else if !positionRegex.MatchString(position) {
-			errors = append(errors, ValidationError{Field: "position", Message: "Position contains invalid characters"})
-		}
+// ruleCodes maps a validation rule to its error code
+var ruleCodes = map[string]string{
+	"required":      i18n.ErrFieldRequired,
+	"gt":            i18n.ErrFieldMustBePositive,
+	"min":           i18n.ErrFieldMustBePositive,
+	"max":           i18n.ErrFieldTooLarge,
+	"email_address": i18n.ErrFieldInvalidEmail,
+	"salary_range":  i18n.ErrSalaryRangeInvalid,
+	"oneof":         i18n.ErrFieldOneOf,
+	"job_cursor":    i18n.ErrCursorInvalid,
+	"without_page":  i18n.ErrCursorWithPage,
+	"job_sort":      i18n.ErrSortInvalid,
+	"search_query":  i18n.ErrSortRequiresQuery,
+	"cursor_sort":   i18n.ErrCursorSortMismatch,
+	"job_facets":    i18n.ErrFacetsInvalid,
+}
 
-		// Validate company
-		company := c.PostForm("company")
-		if company == "" {
-			errors = append(errors, ValidationError{Field: "company", Message: "Company is required"})
-		} else if !companyRegex.MatchString(company) {
-			errors = append(errors, ValidationError{Field: "company", Message: "Company contains invalid characters"})
+// fieldCodes overrides the error code for a specific field and rule
+var fieldCodes = map[string]string{
+	"page.min":  i18n.ErrPageInvalid,
+	"limit.min": i18n.ErrLimitOutOfRange,
+	"limit.max": i18n.ErrLimitOutOfRange,
+
+	"description.max": i18n.ErrFieldTooLong,
+	"q.max":           i18n.ErrFieldTooLong,
+}
+
+func init() {
+	registerValidators()
+}
+
+// registerValidators registers the custom binding rules used by request DTOs
+func registerValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report fields by their request name instead of the Go field name
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"form", "json"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name != "" && name != "-" {
+				return name
+			}
 		}
+		return field.Name
+	})
+
+	v.RegisterValidation("position", matchRegex(positionRegex))
+	v.RegisterValidation("company", matchRegex(companyRegex))
+	v.RegisterValidation("location", matchRegex(locationRegex))
+	v.RegisterValidation("person_name", matchRegex(nameRegex))
+	v.RegisterValidation("email_address", matchRegex(emailRegex))
+	v.RegisterValidation("phone", matchRegex(phoneRegex))
+	v.RegisterValidation("job_cursor", validateJobCursor)
+	v.RegisterValidation("job_sort", validateJobSort)
+	v.RegisterValidation("job_facets", validateJobFacets)
+
+	v.RegisterStructValidation(validateSalaryRange, models.JobInput{})
+	v.RegisterStructValidation(validateJobListQuery, models.JobListQuery{})
+}
+
+// matchRegex builds a validation rule from a regular expression
+func matchRegex(re *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return re.MatchString(fl.Field().String())
+	}
+}
+
+// validateSalaryRange ensures the minimum salary does not exceed the maximum
+func validateSalaryRange(sl validator.StructLevel) {
+	input := sl.Current().Interface().(models.JobInput)
+	if input.SalaryMin > 0 && input.SalaryMax > 0 && input.SalaryMin > input.SalaryMax {
+		sl.ReportError(input.SalaryMin, "salary_range", "SalaryMin", "salary_range", "")
+	}
+}
+
+// validateJobCursor ensures a cursor was returned by a job listing
+func validateJobCursor(fl validator.FieldLevel) bool {
+	_, err := models.DecodeJobCursor(fl.Field().String())
+	return err == nil
+}
+
+// validateJobSort ensures a sort lists known keys and directions
+func validateJobSort(fl validator.FieldLevel) bool {
+	_, err := models.ParseJobSort(fl.Field().String())
+	return err == nil
+}
 
-		// Validate location
-		location := c.PostForm("location")
-		if location == "" {
-			errors = append(errors, ValidationError{Field: "location", Message: "Location is required"})
-		} else if !locationRegex.MatchString(location) {
-			errors = append(errors, ValidationError{Field: "location", Message: "Location contains invalid characters"})
+// validateJobFacets ensures a facet list names known facets
+func validateJobFacets(fl validator.FieldLevel) bool {
+	_, err := models.ParseJobFacets(fl.Field().String())
+	return err == nil
+}
+
+// validateJobListQuery ensures a listing is not selected by both a cursor
+// and a page, that a cursor is used with the sort it was returned with, and
+// that sorting by relevance comes with a search query
+func validateJobListQuery(sl validator.StructLevel) {
+	query := sl.Current().Interface().(models.JobListQuery)
+
+	sort, err := models.ParseJobSort(query.Sort)
+	if query.Sort == "" || err != nil {
+		sort = nil
+	}
+
+	if query.Cursor != "" {
+		if query.Page != nil {
+			sl.ReportError(query.Cursor, "cursor", "Cursor", "without_page", "")
 		}
 
-		// Validate salary_min
-		salaryMinStr := c.PostForm("salary_min")
-		if salaryMinStr == "" {
-			errors = append(errors, ValidationError{Field: "salary_min", Message: "Minimum salary is required"})
-		} else {
-			salaryMin, err := strconv.Atoi(salaryMinStr)
-			if err != nil {
-				errors = append(errors, ValidationError{Field: "salary_min", Message: "Minimum salary must be a valid number"})
-			} else if salaryMin < 0 {
-				errors = append(errors, ValidationError{Field: "salary_min", Message: "Minimum salary must be positive"})
+		if cursor, err := models.DecodeJobCursor(query.Cursor); err == nil {
+			if sort != nil && !sort.Equal(cursor.Sort) {
+				sl.ReportError(query.Cursor, "cursor", "Cursor", "cursor_sort", "")
 			}
+			sort = cursor.Sort
 		}
+	}
 
-		// Validate salary_max
-		salaryMaxStr := c.PostForm("salary_max")
-		if salaryMaxStr == "" {
-			errors = append(errors, ValidationError{Field: "salary_max", Message: "Maximum salary is required"})
-		} else {
-			salaryMax, err := strconv.Atoi(salaryMaxStr)
-			if err != nil {
-				errors = append(errors, ValidationError{Field: "salary_max", Message: "Maximum salary must be a valid number"})
-			} else if salaryMax < 0 {
-				errors = append(errors, ValidationError{Field: "salary_max", Message: "Maximum salary must be positive"})
-			}
+	if sort.Uses(models.SortRelevance) && query.Q == "" {
+		sl.ReportError(query.Sort, "sort", "Sort", "search_query", "")
+	}
+}
+
+// bindRequest binds the request into obj and returns every validation error.
+// A nil binding selects the binder from the request Content-Type, so JSON and
+// form bodies go through the same rules. Fields with the wrong type are
+// reported individually instead of hiding the errors of the other fields.
+func bindRequest(c *gin.Context, obj interface{}, b binding.Binding) []ValidationError {
+	if b == nil {
+		b = binding.Default(c.Request.Method, c.ContentType())
+	}
+
+	var errors []ValidationError
+	var err error
+	if b == binding.JSON {
+		errors, err = decodeJSON(c, obj)
+	} else {
+		errors, err = decodeForm(c, obj, b)
+	}
+
+	if err != nil {
+		if isPayloadTooLarge(err) {
+			return []ValidationError{payloadTooLargeError(c)}
 		}
+		return []ValidationError{newValidationError(c, "body", i18n.ErrInvalidRequestData)}
+	}
 
-		// Validate salary range
-		if len(errors) == 0 {
-			salaryMin, _ := strconv.Atoi(salaryMinStr)
-			salaryMax, _ := strconv.Atoi(salaryMaxStr)
-			if salaryMin > salaryMax {
-				errors = append(errors, ValidationError{Field: "salary_range", Message: "Minimum salary cannot be greater than maximum salary"})
+	// Sanitize after decoding so JSON bodies and query strings are covered too
+	sanitize.Struct(obj)
+
+	// Fields that failed to decode are already reported
+	reported := make(map[string]bool)
+	for _, e := range errors {
+		reported[e.Field] = true
+	}
+
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		for _, e := range translateValidationError(c, err) {
+			if !reported[e.Field] {
+				errors = append(errors, e)
 			}
 		}
+	}
 
-		if len(errors) > 0 {
-			c.JSON(http.StatusBadRequest, ValidationResponse{
-				Error:   "Validation failed",
-				Details: errors,
-			})
-			c.Abort()
-			return
+	return errors
+}
+
+// decodeJSON decodes a JSON body into obj, skipping numeric fields that are not numbers
+func decodeJSON(c *gin.Context, obj interface{}) ([]ValidationError, error) {
+	data, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the body readable for later handlers
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	raw := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
 		}
+	}
 
-		c.Next()
+	var errors []ValidationError
+	for _, field := range numericFields(obj, "json") {
+		value, ok := raw[field]
+		if !ok {
+			continue
+		}
+
+		var number float64
+		if err := json.Unmarshal(value, &number); err != nil || number != float64(int64(number)) {
+			errors = append(errors, newValidationError(c, field, i18n.ErrFieldInvalidNumber))
+			delete(raw, field)
+		}
+	}
+
+	cleaned, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
 	}
+
+	return errors, json.Unmarshal(cleaned, obj)
 }
 
-// ValidateApplicationInput validates application submission input
-func ValidateApplicationInput() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var errors []ValidationError
-
-		// Validate name
-		name := c.PostForm("name")
-		if name == "" {
-			errors = append(errors, ValidationError{Field: "name", Message: "Name is required"})
-		} else if !nameRegex.MatchString(name) {
-			errors = append(errors, ValidationError{Field: "name", Message: "Name contains invalid characters"})
+// decodeForm decodes form or query values into obj, skipping numeric fields that are not numbers
+func decodeForm(c *gin.Context, obj interface{}, b binding.Binding) ([]ValidationError, error) {
+	var values url.Values
+	if b == binding.Query {
+		values = c.Request.URL.Query()
+	} else {
+		if err := c.Request.ParseMultipartForm(defaultMultipartMemory); err != nil && err != http.ErrNotMultipart {
+			return nil, err
 		}
+		values = c.Request.Form
+	}
 
-		// Validate email
-		email := c.PostForm("email")
-		if email == "" {
-			errors = append(errors, ValidationError{Field: "email", Message: "Email is required"})
-		} else if !emailRegex.MatchString(email) {
-			errors = append(errors, ValidationError{Field: "email", Message: "Invalid email format"})
+	form := make(map[string][]string, len(values))
+	for key, value := range values {
+		form[key] = value
+	}
+
+	var errors []ValidationError
+	for _, field := range numericFields(obj, "form") {
+		value := values.Get(field)
+		if value == "" {
+			continue
 		}
 
-		// Validate job_id
-		jobIDStr := c.PostForm("job_id")
-		if jobIDStr == "" {
-			errors = append(errors, ValidationError{Field: "job_id", Message: "Job ID is required"})
-		} else {
-			jobID, err := strconv.Atoi(jobIDStr)
-			if err != nil {
-				errors = append(errors, ValidationError{Field: "job_id", Message: "Job ID must be a valid number"})
-			} else if jobID <= 0 {
-				errors = append(errors, ValidationError{Field: "job_id", Message: "Job ID must be positive"})
-			}
+		if _, err := strconv.Atoi(value); err != nil {
+			errors = append(errors, newValidationError(c, field, i18n.ErrFieldInvalidNumber))
+			delete(form, field)
+		}
+	}
+
+	return errors, binding.MapFormWithTag(obj, form, "form")
+}
+
+// numericFields returns the request names of the integer fields of obj
+func numericFields(obj interface{}, tag string) []string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		kind := field.Type.Kind()
+		if kind == reflect.Ptr {
+			kind = field.Type.Elem().Kind()
 		}
 
-		// Validate CV file
-		file, err := c.FormFile("cv")
-		if err != nil {
-			errors = append(errors, ValidationError{Field: "cv", Message: "CV file is required"})
-		} else {
-			// Validate file type
-			if !strings.HasSuffix(strings.ToLower(file.Filename), ".pdf") {
-				errors = append(errors, ValidationError{Field: "cv", Message: "Only PDF files are allowed"})
+		switch kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name != "" && name != "-" {
+				fields = append(fields, name)
 			}
+		}
+	}
 
-			// Validate file size (max 5MB)
-			if file.Size > 5*1024*1024 {
-				errors = append(errors, ValidationError{Field: "cv", Message: "File size must be less than 5MB"})
-			}
+	return fields
+}
 
-			// Validate filename
-			if len(file.Filename) > 255 {
-				errors = append(errors, ValidationError{Field: "cv", Message: "Filename too long"})
-			}
+// newValidationError creates a validation error for field, localized for the request.
+// The field label is passed to the message as its first argument.
+func newValidationError(c *gin.Context, field, code string, args ...interface{}) ValidationError {
+	args = append([]interface{}{i18n.Label(GetLocale(c), field)}, args...)
+	return ValidationError{Field: field, Code: code, Message: Localize(c, code, args...)}
+}
+
+// translateValidationError converts validator errors into ValidationErrors
+func translateValidationError(c *gin.Context, err error) []ValidationError {
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []ValidationError{newValidationError(c, "body", i18n.ErrInvalidRequestData)}
+	}
+
+	var errors []ValidationError
+	for _, fe := range fieldErrors {
+		// List the values allowed by oneof, which are separated by spaces
+		param := fe.Param()
+		if fe.Tag() == "oneof" {
+			param = strings.ReplaceAll(param, " ", ", ")
 		}
+		errors = append(errors, newValidationError(c, fe.Field(), validationCode(fe), param))
+	}
 
-		if len(errors) > 0 {
-			c.JSON(http.StatusBadRequest, ValidationResponse{
-				Error:   "Validation failed",
-				Details: errors,
-			})
-			c.Abort()
+	return errors
+}
+
+// validationCode returns the error code for a failed validation rule
+func validationCode(fe validator.FieldError) string {
+	if code, ok := fieldCodes[fe.Field()+"."+fe.Tag()]; ok {
+		return code
+	}
+
+	if code, ok := ruleCodes[fe.Tag()]; ok {
+		return code
+	}
+
+	// Remaining rules are the character set checks
+	return i18n.ErrFieldInvalidCharacters
+}
+
+// ValidateJobInput validates job creation input sent as JSON or form data
+func ValidateJobInput() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.JobInput
+		if errors := bindRequest(c, &input, nil); len(errors) > 0 {
+			ValidationErrorResponse(c, i18n.ErrValidationFailed, errors)
 			return
 		}
 
+		c.Set(JobInputKey, input)
 		c.Next()
 	}
 }
 
-// ValidateQueryParams validates query parameters
-func ValidateQueryParams() gin.HandlerFunc {
+// ValidateApplicationInput validates application submission input
+func ValidateApplicationInput() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var errors []ValidationError
-
-		// Validate page parameter
-		pageStr := c.Query("page")
-		if pageStr != "" {
-			page, err := strconv.Atoi(pageStr)
-			if err != nil || page < 1 {
-				errors = append(errors, ValidationError{Field: "page", Message: "Page must be a positive number"})
-			}
-		}
+		var input models.ApplicationInput
+		errors := bindRequest(c, &input, nil)
 
-		// Validate limit parameter
-		limitStr := c.Query("limit")
-		if limitStr != "" {
-			limit, err := strconv.Atoi(limitStr)
-			if err != nil || limit < 1 || limit > 100 {
-				errors = append(errors, ValidationError{Field: "limit", Message: "Limit must be between 1 and 100"})
-			}
-		}
+		// Validate CV file
+		errors = append(errors, validateCVFile(c)...)
 
-		// Validate salary_min parameter
-		salaryMinStr := c.Query("salary_min")
-		if salaryMinStr != "" {
-			salaryMin, err := strconv.Atoi(salaryMinStr)
-			if err != nil || salaryMin < 0 {
-				errors = append(errors, ValidationError{Field: "salary_min", Message: "Minimum salary must be a positive number"})
-			}
+		if len(errors) > 0 {
+			ValidationErrorResponse(c, i18n.ErrValidationFailed, errors)
+			return
 		}
 
-		// Validate salary_max parameter
-		salaryMaxStr := c.Query("salary_max")
-		if salaryMaxStr != "" {
-			salaryMax, err := strconv.Atoi(salaryMaxStr)
-			if err != nil || salaryMax < 0 {
-				errors = append(errors, ValidationError{Field: "salary_max", Message: "Maximum salary must be a positive number"})
-			}
-		}
+		c.Set(ApplicationInputKey, input)
+		c.Next()
+	}
+}
 
-		// Validate location parameter
-		location := c.Query("location")
-		if location != "" && !locationRegex.MatchString(location) {
-			errors = append(errors, ValidationError{Field: "location", Message: "Location contains invalid characters"})
+// ValidateCVUpload validates a CV replacement upload
+func ValidateCVUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if errors := validateCVFile(c); len(errors) > 0 {
+			ValidationErrorResponse(c, i18n.ErrValidationFailed, errors)
+			return
 		}
 
-		if len(errors) > 0 {
-			c.JSON(http.StatusBadRequest, ValidationResponse{
-				Error:   "Invalid query parameters",
-				Details: errors,
-			})
-			c.Abort()
+		c.Next()
+	}
+}
+
+// ValidateMagicLinkRequest validates a request for a new magic link
+func ValidateMagicLinkRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.MagicLinkInput
+		if errors := bindRequest(c, &input, nil); len(errors) > 0 {
+			ValidationErrorResponse(c, i18n.ErrValidationFailed, errors)
 			return
 		}
 
+		c.Set(MagicLinkInputKey, input)
 		c.Next()
 	}
 }
 
-// SanitizeInput sanitizes input to prevent XSS and injection attacks
-func SanitizeInput() gin.HandlerFunc {
+// ValidateQueryParams validates job listing query parameters
+func ValidateQueryParams() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Sanitize form data
-		for key, values := range c.Request.PostForm {
-			for i, value := range values {
-				// Remove potentially dangerous characters
-				sanitized := strings.TrimSpace(value)
-				sanitized = strings.ReplaceAll(sanitized, "<script>", "")
-				sanitized = strings.ReplaceAll(sanitized, "</script>", "")
-				sanitized = strings.ReplaceAll(sanitized, "javascript:", "")
-				sanitized = strings.ReplaceAll(sanitized, "onload=", "")
-				sanitized = strings.ReplaceAll(sanitized, "onerror=", "")
-				c.Request.PostForm[key][i] = sanitized
-			}
+		var query models.JobListQuery
+		if errors := bindRequest(c, &query, binding.Query); len(errors) > 0 {
+			ValidationErrorResponse(c, i18n.ErrInvalidQueryParameters, errors)
+			return
 		}
 
-		// Sanitize query parameters
-		for key, values := range c.Request.URL.Query() {
-			for i, value := range values {
-				sanitized := strings.TrimSpace(value)
-				sanitized = strings.ReplaceAll(sanitized, "<script>", "")
-				sanitized = strings.ReplaceAll(sanitized, "</script>", "")
-				sanitized = strings.ReplaceAll(sanitized, "javascript:", "")
-				c.Request.URL.Query()[key][i] = sanitized
-			}
+		c.Set(JobListQueryKey, query)
+		c.Next()
+	}
+}
+
+// validateCVFile validates the uploaded "cv" form file
+func validateCVFile(c *gin.Context) []ValidationError {
+	var errors []ValidationError
+
+	file, err := c.FormFile("cv")
+	if err != nil {
+		if isPayloadTooLarge(err) {
+			return append(errors, payloadTooLargeError(c))
 		}
+		errors = append(errors, newValidationError(c, "cv", i18n.ErrCVRequired))
+		return errors
+	}
 
-		c.Next()
+	// Validate file type
+	if !strings.HasSuffix(strings.ToLower(file.Filename), ".pdf") {
+		errors = append(errors, newValidationError(c, "cv", i18n.ErrCVPDFOnly))
+	}
+
+	// Validate file size (max 5MB)
+	if file.Size > 5*1024*1024 {
+		errors = append(errors, newValidationError(c, "cv", i18n.ErrCVTooLarge))
+	}
+
+	// Validate filename
+	if len(file.Filename) > 255 {
+		errors = append(errors, newValidationError(c, "cv", i18n.ErrCVFilenameTooLong))
 	}
+
+	return errors
 }
@@ -1,11 +1,24 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"job-portal-backend/database"
+	"strings"
 	"time"
 )
 
+// Application statuses
+const (
+	ApplicationStatusSubmitted = "submitted"
+	ApplicationStatusWithdrawn = "withdrawn"
+)
+
+// ErrApplicationWithdrawn is returned when changing an application that was
+// already withdrawn, possibly by a concurrent request
+var ErrApplicationWithdrawn = errors.New("application withdrawn")
+
 // Application represents a job application
 // @Description Job application information
 type Application struct {
@@ -13,40 +26,160 @@ type Application struct {
 	JobID      int       `json:"job_id" example:"1"`
 	Name       string    `json:"name" example:"John Doe"`
 	Email      string    `json:"email" example:"john.doe@example.com"`
-	CVFilename string    `json:"cv_filename" example:"cv_1234567890.pdf"`
+	CVFilename string    `json:"cv_filename" example:"cv_wcpswhoinpfplsxqugz3l4akf2.pdf"`
+	Status     string    `json:"status" example:"submitted"`
 	AppliedAt  time.Time `json:"applied_at" example:"2025-01-15T10:30:00Z"`
+	UpdatedAt  time.Time `json:"updated_at" example:"2025-01-15T10:30:00Z"`
 	Job        *Job      `json:"job,omitempty"`
 }
 
-func CreateApplication(app *Application) error {
-	query := `INSERT INTO applications (job_id, name, email, cv_filename) 
-			  VALUES ($1, $2, $3, $4) RETURNING id, applied_at`
+// ApplicationInput represents the form fields of an application submission
+// @Description Job application payload, the CV is uploaded separately as the "cv" file
+type ApplicationInput struct {
+	JobID int    `json:"job_id" form:"job_id" binding:"required,gt=0" minimum:"1" example:"1"`
+	Name  string `json:"name" form:"name" binding:"required,person_name" sanitize:"text" minLength:"2" maxLength:"50" example:"John Doe"`
+	Email string `json:"email" form:"email" binding:"required,email_address" sanitize:"text" example:"john.doe@example.com"`
+}
+
+// MagicLinkInput represents a request for a new candidate magic link
+// @Description Magic link request payload
+type MagicLinkInput struct {
+	Email string `json:"email" form:"email" binding:"required,email_address" sanitize:"text" example:"john.doe@example.com"`
+}
+
+func CreateApplication(ctx context.Context, app *Application) (err error) {
+	// The application count of the job is kept in the same statement, so
+	// both change together
+	query := `WITH application AS (
+				  INSERT INTO applications (job_id, name, email, cv_filename)
+				  VALUES ($1, $2, $3, $4) RETURNING id, status, applied_at, updated_at
+			  ), counted AS (
+				  UPDATE jobs SET applications_count = applications_count + 1 WHERE id = $1
+			  )
+			  SELECT id, status, applied_at, updated_at FROM application`
+
+	ctx, end := database.StartQuery(ctx, "create_application", query)
+	defer func() { end(err) }()
+
+	return database.DB.QueryRowContext(ctx, query, app.JobID, app.Name, app.Email, app.CVFilename).
+		Scan(&app.ID, &app.Status, &app.AppliedAt, &app.UpdatedAt)
+}
+
+func GetApplications(ctx context.Context) (applications []Application, err error) {
+	query := `
+		SELECT a.id, a.job_id, a.name, a.email, a.cv_filename, a.status, a.applied_at, a.updated_at,
+			   j.position, j.company, j.location, j.salary_min, j.salary_max, j.created_at
+		FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		ORDER BY a.applied_at DESC
+	`
+
+	ctx, end := database.StartQuery(ctx, "get_applications", query)
+	defer func() { end(err) }()
 
-	return database.DB.QueryRow(query, app.JobID, app.Name, app.Email, app.CVFilename).
-		Scan(&app.ID, &app.AppliedAt)
+	err = database.Read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		applications, err = scanApplications(rows)
+		return err
+	})
+	return applications, err
 }
 
-func GetApplications() ([]Application, error) {
+// GetApplicationsByEmail returns every application submitted with the given email
+func GetApplicationsByEmail(ctx context.Context, email string) (applications []Application, err error) {
 	query := `
-		SELECT a.id, a.job_id, a.name, a.email, a.cv_filename, a.applied_at,
+		SELECT a.id, a.job_id, a.name, a.email, a.cv_filename, a.status, a.applied_at, a.updated_at,
 			   j.position, j.company, j.location, j.salary_min, j.salary_max, j.created_at
 		FROM applications a
 		JOIN jobs j ON a.job_id = j.id
+		WHERE LOWER(a.email) = LOWER($1)
 		ORDER BY a.applied_at DESC
 	`
 
-	rows, err := database.DB.Query(query)
+	ctx, end := database.StartQuery(ctx, "get_applications_by_email", query)
+	defer func() { end(err) }()
+
+	err = database.Read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query, email)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		applications, err = scanApplications(rows)
+		return err
+	})
+	return applications, err
+}
+
+// GetApplicationByIDAndEmail returns an application only if it belongs to email
+func GetApplicationByIDAndEmail(ctx context.Context, id int, email string) (*Application, error) {
+	app, err := GetApplicationByID(ctx, id)
+	if err != nil || app == nil {
+		return app, err
+	}
+
+	if !strings.EqualFold(app.Email, email) {
+		return nil, nil
+	}
+
+	return app, nil
+}
+
+// WithdrawApplication marks an application as withdrawn
+func WithdrawApplication(ctx context.Context, id int) (err error) {
+	query := `UPDATE applications SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status <> $1`
+
+	ctx, end := database.StartQuery(ctx, "withdraw_application", query)
+	defer func() { end(err) }()
+
+	result, err := database.DB.ExecContext(ctx, query, ApplicationStatusWithdrawn, id)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	return requireChanged(result)
+}
+
+// UpdateApplicationCV replaces the CV file of an application
+func UpdateApplicationCV(ctx context.Context, id int, cvFilename string) (err error) {
+	query := `UPDATE applications SET cv_filename = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status <> $3`
+
+	ctx, end := database.StartQuery(ctx, "update_application_cv", query)
+	defer func() { end(err) }()
 
+	result, err := database.DB.ExecContext(ctx, query, cvFilename, id, ApplicationStatusWithdrawn)
+	if err != nil {
+		return err
+	}
+	return requireChanged(result)
+}
+
+// requireChanged returns ErrApplicationWithdrawn when an update guarded by the
+// application status changed no row
+func requireChanged(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrApplicationWithdrawn
+	}
+	return nil
+}
+
+// scanApplications scans application rows joined with their job
+func scanApplications(rows *sql.Rows) ([]Application, error) {
 	var applications []Application
 	for rows.Next() {
 		var app Application
 		var job Job
 		err := rows.Scan(
-			&app.ID, &app.JobID, &app.Name, &app.Email, &app.CVFilename, &app.AppliedAt,
+			&app.ID, &app.JobID, &app.Name, &app.Email, &app.CVFilename, &app.Status, &app.AppliedAt, &app.UpdatedAt,
 			&job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.CreatedAt,
 		)
 		if err != nil {
@@ -56,24 +189,29 @@ func GetApplications() ([]Application, error) {
 		applications = append(applications, app)
 	}
 
-	return applications, nil
+	return applications, rows.Err()
 }
 
-func GetApplicationByID(id int) (*Application, error) {
+func GetApplicationByID(ctx context.Context, id int) (_ *Application, err error) {
 	query := `
-		SELECT a.id, a.job_id, a.name, a.email, a.cv_filename, a.applied_at,
+		SELECT a.id, a.job_id, a.name, a.email, a.cv_filename, a.status, a.applied_at, a.updated_at,
 			   j.position, j.company, j.location, j.salary_min, j.salary_max, j.created_at
 		FROM applications a
 		JOIN jobs j ON a.job_id = j.id
 		WHERE a.id = $1
 	`
 
+	ctx, end := database.StartQuery(ctx, "get_application_by_id", query)
+	defer func() { end(err) }()
+
 	var app Application
 	var job Job
-	err := database.DB.QueryRow(query, id).Scan(
-		&app.ID, &app.JobID, &app.Name, &app.Email, &app.CVFilename, &app.AppliedAt,
-		&job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.CreatedAt,
-	)
+	err = database.Read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, query, id).Scan(
+			&app.ID, &app.JobID, &app.Name, &app.Email, &app.CVFilename, &app.Status, &app.AppliedAt, &app.UpdatedAt,
+			&job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.CreatedAt,
+		)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
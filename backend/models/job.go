@@ -1,155 +1,333 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"job-portal-backend/database"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Employment types of a job
+const (
+	EmploymentFullTime   = "full_time"
+	EmploymentPartTime   = "part_time"
+	EmploymentContract   = "contract"
+	EmploymentInternship = "internship"
+)
+
+// EmploymentTypes lists the employment types of a job
+var EmploymentTypes = []string{EmploymentFullTime, EmploymentPartTime, EmploymentContract, EmploymentInternship}
+
 // Job represents a job posting
 // @Description Job posting information
 type Job struct {
-	ID        int       `json:"id" example:"1"`
-	Position  string    `json:"position" example:"Frontend Developer"`
-	Company   string    `json:"company" example:"TechCorp Indonesia"`
-	Location  string    `json:"location" example:"Jakarta"`
-	SalaryMin int       `json:"salary_min" example:"3000000"`
-	SalaryMax int       `json:"salary_max" example:"5000000"`
-	CreatedAt time.Time `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	ID          int       `json:"id" example:"1"`
+	Position    string    `json:"position" example:"Frontend Developer"`
+	Company     string    `json:"company" example:"TechCorp Indonesia"`
+	Location    string    `json:"location" example:"Jakarta"`
+	SalaryMin   int       `json:"salary_min" example:"3000000"`
+	SalaryMax   int       `json:"salary_max" example:"5000000"`
+	Description string    `json:"description,omitempty" example:"<p>Build <strong>responsive</strong> interfaces with React.</p>"`
+	CreatedAt   time.Time `json:"created_at" example:"2025-01-15T10:30:00Z"`
+
+	EmploymentType string `json:"employment_type" enums:"full_time,part_time,contract,internship" example:"full_time"`
+
+	// ApplicationsCount is the number of applications submitted for the job
+	ApplicationsCount int `json:"applications_count" example:"12"`
+
+	// Relevance ranks the job against the search query of a listing
+	Relevance float64 `json:"relevance,omitempty" example:"0.0608"`
 }
 
 // JobFilter represents filters for job search
 // @Description Job search filters
 type JobFilter struct {
-	Location  string `json:"location" example:"Jakarta"`
-	SalaryMin int    `json:"salary_min" example:"2000000"`
-	SalaryMax int    `json:"salary_max" example:"8000000"`
+	Query          string `json:"q" example:"react developer"`
+	Location       string `json:"location" example:"Jakarta"`
+	SalaryMin      int    `json:"salary_min" example:"2000000"`
+	SalaryMax      int    `json:"salary_max" example:"8000000"`
+	EmploymentType string `json:"employment_type" example:"full_time"`
+	Company        string `json:"company" example:"TechCorp Indonesia"`
 }
 
-func GetAllJobs(filters JobFilter) ([]Job, error) {
-	query := "SELECT id, position, company, location, salary_min, salary_max, created_at FROM jobs WHERE 1=1"
-	args := []interface{}{}
-	argIndex := 1
+// JobInput represents the request body for creating a job
+// @Description Job creation payload, accepted as JSON or form data
+type JobInput struct {
+	Position  string `json:"position" form:"position" binding:"required,position" sanitize:"text" minLength:"2" maxLength:"100" example:"Frontend Developer"`
+	Company   string `json:"company" form:"company" binding:"required,company" sanitize:"text" minLength:"2" maxLength:"100" example:"TechCorp Indonesia"`
+	Location  string `json:"location" form:"location" binding:"required,location" sanitize:"text" minLength:"2" maxLength:"50" example:"Jakarta"`
+	SalaryMin int    `json:"salary_min" form:"salary_min" binding:"required,gt=0" minimum:"1" example:"3000000"`
+	SalaryMax int    `json:"salary_max" form:"salary_max" binding:"required,gt=0" minimum:"1" example:"5000000"`
 
-	if filters.Location != "" {
-		query += " AND location = $" + string(rune(argIndex+'0'))
-		args = append(args, filters.Location)
-		argIndex++
-	}
+	// Description allows basic HTML and markdown; anything else is stripped
+	Description string `json:"description" form:"description" binding:"max=5000" sanitize:"html" maxLength:"5000" example:"<p>Build <strong>responsive</strong> interfaces with React.</p>"`
 
-	if filters.SalaryMin > 0 {
-		query += " AND salary_max >= $" + string(rune(argIndex+'0'))
-		args = append(args, filters.SalaryMin)
-		argIndex++
-	}
+	// EmploymentType defaults to full_time
+	EmploymentType string `json:"employment_type" form:"employment_type" binding:"omitempty,oneof=full_time part_time contract internship" enums:"full_time,part_time,contract,internship" example:"full_time"`
+}
 
-	if filters.SalaryMax > 0 {
-		query += " AND salary_min <= $" + string(rune(argIndex+'0'))
-		args = append(args, filters.SalaryMax)
-		argIndex++
+// ToJob converts validated input into a job
+func (in JobInput) ToJob() Job {
+	return Job{
+		Position:    in.Position,
+		Company:     in.Company,
+		Location:    in.Location,
+		SalaryMin:   in.SalaryMin,
+		SalaryMax:   in.SalaryMax,
+		Description: in.Description,
+
+		EmploymentType: in.EmploymentType,
 	}
+}
 
-	query += " ORDER BY created_at DESC"
+// JobListQuery represents the query parameters for listing jobs
+// @Description Job listing query parameters
+type JobListQuery struct {
+	Page      *int   `form:"page" binding:"omitempty,min=1" minimum:"1" example:"1"`
+	Limit     *int   `form:"limit" binding:"omitempty,min=1,max=50" minimum:"1" maximum:"50" example:"12"`
+	Q         string `form:"q" binding:"omitempty,max=100" sanitize:"text" maxLength:"100" example:"react developer"`
+	Location  string `form:"location" binding:"omitempty,location" sanitize:"text" example:"Jakarta"`
+	SalaryMin int    `form:"salary_min" binding:"omitempty,min=0" minimum:"0" example:"2000000"`
+	SalaryMax int    `form:"salary_max" binding:"omitempty,min=0" minimum:"0" example:"8000000"`
+
+	EmploymentType string `form:"employment_type" binding:"omitempty,oneof=full_time part_time contract internship" enums:"full_time,part_time,contract,internship" example:"full_time"`
+	Company        string `form:"company" binding:"omitempty,company" sanitize:"text" example:"TechCorp Indonesia"`
+
+	Cursor string `form:"cursor" binding:"omitempty,job_cursor" example:"eyJzIjoiY3JlYXRlZF9hdDpkZXNjIiwidiI6WyIyMDI1LTAxLTE1VDEwOjMwOjAwWiIsNDJdfQ"`
+	Count  string `form:"count" binding:"omitempty,oneof=exact estimate none" enums:"exact,estimate,none" example:"exact"`
+	Sort   string `form:"sort" binding:"omitempty,job_sort" example:"salary_max:desc,created_at:desc"`
+	Facets string `form:"facets" binding:"omitempty,job_facets" example:"location,salary"`
+}
 
-	rows, err := database.DB.Query(query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// Count modes of a job listing
+const (
+	// CountExact counts every matching job
+	CountExact = "exact"
 
-	var jobs []Job
-	for rows.Next() {
-		var job Job
-		err := rows.Scan(&job.ID, &job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.CreatedAt)
-		if err != nil {
-			return nil, err
-		}
-		jobs = append(jobs, job)
-	}
+	// CountEstimate uses the query planner's estimate, which is cheap but approximate
+	CountEstimate = "estimate"
+
+	// CountNone skips counting
+	CountNone = "none"
+)
+
+// jobColumns are the columns of a job, in the order scanned by scanJobs
+const jobColumns = "id, position, company, location, salary_min, salary_max, description, created_at, applications_count, employment_type"
+
+// jobQuery builds the conditions of a job listing and their arguments
+type jobQuery struct {
+	conditions []string
+	args       []interface{}
 
-	return jobs, nil
+	// search is the text search query, empty without one
+	search string
 }
 
-func GetJobsWithPagination(filters JobFilter, page, limit int) ([]Job, int, error) {
-	// Build base query for counting total
-	countQuery := "SELECT COUNT(*) FROM jobs WHERE 1=1"
-	args := []interface{}{}
-	argIndex := 1
+// jobCondition is a condition of a job filter and the facet it narrows
+type jobCondition struct {
+	facet string
+	sql   string
+}
 
-	if filters.Location != "" {
-		countQuery += " AND location = $" + string(rune(argIndex+'0'))
-		args = append(args, filters.Location)
-		argIndex++
+// newJobQuery returns the query selecting the jobs matching filters
+func newJobQuery(filters JobFilter) *jobQuery {
+	q := &jobQuery{}
+	q.searchFor(filters.Query)
+	for _, condition := range q.filterConditions(filters) {
+		q.where(condition.sql)
+	}
+	return q
+}
+
+// searchFor restricts the query to the jobs matching the text search query
+func (q *jobQuery) searchFor(query string) {
+	if query != "" {
+		q.search = "plainto_tsquery('simple', " + q.arg(query) + ")"
+		q.where("search_vector @@ " + q.search)
 	}
+}
 
+// filterConditions adds the arguments of the filters other than the search
+// query and returns their conditions
+func (q *jobQuery) filterConditions(filters JobFilter) []jobCondition {
+	var conditions []jobCondition
+	if filters.Location != "" {
+		conditions = append(conditions, jobCondition{FacetLocation, "location = " + q.arg(filters.Location)})
+	}
 	if filters.SalaryMin > 0 {
-		countQuery += " AND salary_max >= $" + string(rune(argIndex+'0'))
-		args = append(args, filters.SalaryMin)
-		argIndex++
+		conditions = append(conditions, jobCondition{FacetSalary, "salary_max >= " + q.arg(filters.SalaryMin)})
 	}
-
 	if filters.SalaryMax > 0 {
-		countQuery += " AND salary_min <= $" + string(rune(argIndex+'0'))
-		args = append(args, filters.SalaryMax)
-		argIndex++
+		conditions = append(conditions, jobCondition{FacetSalary, "salary_min <= " + q.arg(filters.SalaryMax)})
 	}
-
-	// Get total count
-	var total int
-	err := database.DB.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+	if filters.EmploymentType != "" {
+		conditions = append(conditions, jobCondition{FacetEmploymentType, "employment_type = " + q.arg(filters.EmploymentType)})
+	}
+	if filters.Company != "" {
+		conditions = append(conditions, jobCondition{FacetCompany, "company = " + q.arg(filters.Company)})
 	}
+	return conditions
+}
 
-	// Build query for getting jobs with pagination
-	query := "SELECT id, position, company, location, salary_min, salary_max, created_at FROM jobs WHERE 1=1"
-	queryArgs := []interface{}{}
-	queryArgIndex := 1
+// arg adds an argument to the query and returns its placeholder
+func (q *jobQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
 
-	if filters.Location != "" {
-		query += " AND location = $" + string(rune(queryArgIndex+'0'))
-		queryArgs = append(queryArgs, filters.Location)
-		queryArgIndex++
+// where adds a condition to the query
+func (q *jobQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// whereClause returns the WHERE clause of the conditions, empty without any
+func (q *jobQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
 	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
 
-	if filters.SalaryMin > 0 {
-		query += " AND salary_max >= $" + string(rune(queryArgIndex+'0'))
-		queryArgs = append(queryArgs, filters.SalaryMin)
-		queryArgIndex++
+// relevance returns the expression ranking jobs against the search query,
+// zero without one
+func (q *jobQuery) relevance() string {
+	if q.search == "" {
+		return "0"
 	}
+	return "ts_rank(search_vector, " + q.search + ")"
+}
 
-	if filters.SalaryMax > 0 {
-		query += " AND salary_min <= $" + string(rune(queryArgIndex+'0'))
-		queryArgs = append(queryArgs, filters.SalaryMax)
-		queryArgIndex++
+// selectJobs returns the start of a listing query, up to its conditions
+func (q *jobQuery) selectJobs() string {
+	return "SELECT " + jobColumns + ", " + q.relevance() + " FROM jobs"
+}
+
+func GetAllJobs(ctx context.Context, filters JobFilter) ([]Job, error) {
+	q := newJobQuery(filters)
+	query := q.selectJobs() + q.whereClause() + DefaultJobSort.orderBy(q, false)
+
+	return listJobs(ctx, "get_all_jobs", query, q.args)
+}
+
+// GetJobsWithPagination returns page of the jobs matching filters in the
+// order of sort. One job more than limit is returned when another page follows.
+func GetJobsWithPagination(ctx context.Context, filters JobFilter, sort JobSort, page, limit int) ([]Job, error) {
+	q := newJobQuery(filters)
+	query := q.selectJobs() + q.whereClause() + sort.orderBy(q, false)
+	query += fmt.Sprintf(" LIMIT %s OFFSET %s", q.arg(limit+1), q.arg((page-1)*limit))
+
+	return listJobs(ctx, "list_jobs", query, q.args)
+}
+
+// GetJobsByCursor returns up to limit jobs matching filters next to cursor,
+// in the order of its sort, seeking on the sort keys instead of skipping
+// rows. One job more than limit is returned when more follow in the
+// direction of the cursor: the last one, or the first one for a Before cursor.
+func GetJobsByCursor(ctx context.Context, filters JobFilter, cursor JobCursor, limit int) ([]Job, error) {
+	q := newJobQuery(filters)
+	q.where(cursor.Sort.keyset(q, cursor))
+
+	// A Before cursor reads backwards from the cursor, then restores the order
+	query := q.selectJobs() + q.whereClause() + cursor.Sort.orderBy(q, cursor.Before)
+	query += " LIMIT " + q.arg(limit+1)
+
+	jobs, err := listJobs(ctx, "list_jobs_by_cursor", query, q.args)
+	if cursor.Before {
+		slices.Reverse(jobs)
 	}
+	return jobs, err
+}
+
+// CountJobs returns the number of jobs matching filters
+func CountJobs(ctx context.Context, filters JobFilter) (total int, err error) {
+	q := newJobQuery(filters)
+	query := "SELECT COUNT(*) FROM jobs" + q.whereClause()
 
-	query += " ORDER BY created_at DESC LIMIT $" + string(rune(queryArgIndex+'0')) + " OFFSET $" + string(rune(queryArgIndex+1+'0'))
-	queryArgs = append(queryArgs, limit, (page-1)*limit)
+	ctx, end := database.StartQuery(ctx, "count_jobs", query)
+	defer func() { end(err) }()
 
-	rows, err := database.DB.Query(query, queryArgs...)
+	err = database.Read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, query, q.args...).Scan(&total)
+	})
+	return total, err
+}
+
+// EstimateJobs returns the query planner's estimate of the number of jobs
+// matching filters, without reading them
+func EstimateJobs(ctx context.Context, filters JobFilter) (total int, err error) {
+	q := newJobQuery(filters)
+	query := "EXPLAIN (FORMAT JSON) SELECT 1 FROM jobs" + q.whereClause()
+
+	ctx, end := database.StartQuery(ctx, "estimate_jobs", query)
+	defer func() { end(err) }()
+
+	var plan []byte
+	err = database.Read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, query, q.args...).Scan(&plan)
+	})
 	if err != nil {
-		return nil, 0, err
+		return 0, err
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil || len(explained) == 0 {
+		return 0, fmt.Errorf("unexpected query plan: %w", err)
 	}
-	defer rows.Close()
+	return int(explained[0].Plan.Rows), nil
+}
+
+// listJobs runs a listing query, which selects jobColumns and the relevance
+func listJobs(ctx context.Context, name, query string, args []interface{}) (jobs []Job, err error) {
+	ctx, end := database.StartQuery(ctx, name, query)
+	defer func() { end(err) }()
+
+	err = database.Read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
 
+		jobs, err = scanJobs(rows)
+		return err
+	})
+	return jobs, err
+}
+
+// scanJobs scans the rows of a listing query
+func scanJobs(rows *sql.Rows) ([]Job, error) {
 	var jobs []Job
 	for rows.Next() {
 		var job Job
-		err := rows.Scan(&job.ID, &job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.CreatedAt)
+		err := rows.Scan(&job.ID, &job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.Description, &job.CreatedAt, &job.ApplicationsCount, &job.EmploymentType, &job.Relevance)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		jobs = append(jobs, job)
 	}
 
-	return jobs, total, nil
+	return jobs, rows.Err()
 }
 
-func GetJobByID(id int) (*Job, error) {
+func GetJobByID(ctx context.Context, id int) (_ *Job, err error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE id = $1"
+
+	ctx, end := database.StartQuery(ctx, "get_job_by_id", query)
+	defer func() { end(err) }()
+
 	var job Job
-	err := database.DB.QueryRow("SELECT id, position, company, location, salary_min, salary_max, created_at FROM jobs WHERE id = $1", id).
-		Scan(&job.ID, &job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.CreatedAt)
+	err = database.Read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, query, id).
+			Scan(&job.ID, &job.Position, &job.Company, &job.Location, &job.SalaryMin, &job.SalaryMax, &job.Description, &job.CreatedAt, &job.ApplicationsCount, &job.EmploymentType)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -161,30 +339,73 @@ func GetJobByID(id int) (*Job, error) {
 	return &job, nil
 }
 
-func CreateJob(job *Job) error {
-	query := `INSERT INTO jobs (position, company, location, salary_min, salary_max) 
-			  VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+func CreateJob(ctx context.Context, job *Job) (err error) {
+	query := `INSERT INTO jobs (position, company, location, salary_min, salary_max, description, employment_type) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
 
-	return database.DB.QueryRow(query, job.Position, job.Company, job.Location, job.SalaryMin, job.SalaryMax).
+	if job.EmploymentType == "" {
+		job.EmploymentType = EmploymentFullTime
+	}
+
+	ctx, end := database.StartQuery(ctx, "create_job", query)
+	defer func() { end(err) }()
+
+	return database.DB.QueryRowContext(ctx, query, job.Position, job.Company, job.Location, job.SalaryMin, job.SalaryMax, job.Description, job.EmploymentType).
 		Scan(&job.ID, &job.CreatedAt)
 }
 
-func GetLocations() ([]string, error) {
-	rows, err := database.DB.Query("SELECT DISTINCT location FROM jobs ORDER BY location")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// GetPopularJobIDs returns the IDs of the limit jobs with the most applications
+func GetPopularJobIDs(ctx context.Context, limit int) (ids []int, err error) {
+	query := `SELECT id FROM jobs
+			  WHERE applications_count > 0
+			  ORDER BY applications_count DESC, id DESC
+			  LIMIT $1`
 
-	var locations []string
-	for rows.Next() {
-		var location string
-		err := rows.Scan(&location)
+	ctx, end := database.StartQuery(ctx, "get_popular_job_ids", query)
+	defer func() { end(err) }()
+
+	err = database.Read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query, limit)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		locations = append(locations, location)
-	}
+		defer rows.Close()
+
+		ids = nil
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return rows.Err()
+	})
+	return ids, err
+}
 
-	return locations, nil
+func GetLocations(ctx context.Context) (locations []string, err error) {
+	query := "SELECT DISTINCT location FROM jobs ORDER BY location"
+
+	ctx, end := database.StartQuery(ctx, "get_locations", query)
+	defer func() { end(err) }()
+
+	err = database.Read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		locations = nil
+		for rows.Next() {
+			var location string
+			if err := rows.Scan(&location); err != nil {
+				return err
+			}
+			locations = append(locations, location)
+		}
+		return rows.Err()
+	})
+	return locations, err
 }
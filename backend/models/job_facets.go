@@ -0,0 +1,219 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"job-portal-backend/database"
+	"slices"
+	"strings"
+)
+
+// Facets of a job listing
+const (
+	FacetLocation       = "location"
+	FacetSalary         = "salary"
+	FacetEmploymentType = "employment_type"
+	FacetCompany        = "company"
+)
+
+// maxCompanyFacets bounds the companies counted, which are the most common
+const maxCompanyFacets = 20
+
+// ErrInvalidFacets is returned when parsing a malformed facet list
+var ErrInvalidFacets = errors.New("invalid facets")
+
+// SalaryBucket is a salary range of the salary facet. It matches the jobs
+// returned by the salary_min and salary_max filters with the same bounds.
+type SalaryBucket struct {
+	Min int
+
+	// Max is zero for the open-ended bucket
+	Max int
+}
+
+// SalaryBuckets are the salary ranges offered by the filter panel
+var SalaryBuckets = []SalaryBucket{
+	{Min: 1000000, Max: 3000000},
+	{Min: 3000000, Max: 5000000},
+	{Min: 5000000, Max: 8000000},
+	{Min: 8000000},
+}
+
+// Key identifies the bucket in the salary facet
+func (b SalaryBucket) Key() string {
+	if b.Max == 0 {
+		return fmt.Sprintf("%d+", b.Min)
+	}
+	return fmt.Sprintf("%d-%d", b.Min, b.Max)
+}
+
+// FacetValue is the number of jobs a filter value would return
+// @Description Number of jobs matching a filter value
+type FacetValue struct {
+	Value string `json:"value" example:"Jakarta"`
+	Count int    `json:"count" example:"12"`
+
+	// Min and Max are the filter bounds of a salary bucket
+	Min int `json:"min,omitempty" example:"3000000"`
+	Max int `json:"max,omitempty" example:"5000000"`
+}
+
+// JobFacets maps a facet to the counts of its values
+type JobFacets map[string][]FacetValue
+
+// JobFacetNames returns the facets of a job listing
+func JobFacetNames() []string {
+	return []string{FacetLocation, FacetSalary, FacetEmploymentType, FacetCompany}
+}
+
+// ParseJobFacets parses a comma-separated list of facets and returns them
+// in the order of JobFacetNames, without duplicates
+func ParseJobFacets(value string) ([]string, error) {
+	requested := strings.Split(value, ",")
+	for i, facet := range requested {
+		requested[i] = strings.TrimSpace(facet)
+		if !slices.Contains(JobFacetNames(), requested[i]) {
+			return nil, ErrInvalidFacets
+		}
+	}
+
+	var facets []string
+	for _, facet := range JobFacetNames() {
+		if slices.Contains(requested, facet) {
+			facets = append(facets, facet)
+		}
+	}
+	return facets, nil
+}
+
+// GetJobFacets counts, for each value of facets, the jobs matching filters
+// with that value. The filter of a facet is left out of its own counts, so
+// they tell how many jobs selecting another value would return. Every facet
+// is counted in one query reading the matching jobs once.
+func GetJobFacets(ctx context.Context, filters JobFilter, facets []string) (_ JobFacets, err error) {
+	q := &jobQuery{}
+	q.searchFor(filters.Query)
+
+	// Each facet gets a column telling whether a job passes its filter
+	matches := make(map[string][]string)
+	for _, condition := range q.filterConditions(filters) {
+		matches[condition.facet] = append(matches[condition.facet], condition.sql)
+	}
+
+	matchColumns := make([]string, 0, len(JobFacetNames()))
+	for _, facet := range JobFacetNames() {
+		match := "TRUE"
+		if len(matches[facet]) > 0 {
+			match = strings.Join(matches[facet], " AND ")
+		}
+		matchColumns = append(matchColumns, fmt.Sprintf("%s AS %s_match", match, facet))
+	}
+
+	// otherMatches selects the jobs passing the filters of every other facet
+	otherMatches := func(facet string) string {
+		var others []string
+		for _, other := range JobFacetNames() {
+			if other != facet {
+				others = append(others, "matching."+other+"_match")
+			}
+		}
+		return strings.Join(others, " AND ")
+	}
+
+	counts := make([]string, 0, len(facets))
+	for _, facet := range facets {
+		switch facet {
+		case FacetLocation, FacetEmploymentType:
+			counts = append(counts, fmt.Sprintf(
+				"SELECT '%[1]s', %[1]s, COUNT(*) FROM matching WHERE %[2]s GROUP BY %[1]s",
+				facet, otherMatches(facet)))
+		case FacetCompany:
+			counts = append(counts, fmt.Sprintf(
+				"(SELECT '%[1]s', %[1]s, COUNT(*) FROM matching WHERE %[2]s GROUP BY %[1]s ORDER BY COUNT(*) DESC, %[1]s LIMIT %[3]d)",
+				facet, otherMatches(facet), maxCompanyFacets))
+		case FacetSalary:
+			buckets := make([]string, len(SalaryBuckets))
+			for i, bucket := range SalaryBuckets {
+				buckets[i] = fmt.Sprintf("(%s::text, %s::integer, %s::integer)", q.arg(bucket.Key()), q.arg(bucket.Min), q.arg(bucket.Max))
+			}
+			counts = append(counts, fmt.Sprintf(
+				"SELECT '%s', bucket.key, COUNT(matching.salary_min) FROM (VALUES %s) AS bucket(key, low, high) "+
+					"LEFT JOIN matching ON matching.salary_max >= bucket.low AND (bucket.high = 0 OR matching.salary_min <= bucket.high) AND %s "+
+					"GROUP BY bucket.key",
+				facet, strings.Join(buckets, ", "), otherMatches(facet)))
+		}
+	}
+
+	query := "WITH matching AS (SELECT location, company, employment_type, salary_min, salary_max, " +
+		strings.Join(matchColumns, ", ") + " FROM jobs" + q.whereClause() + ") " +
+		strings.Join(counts, " UNION ALL ")
+
+	ctx, end := database.StartQuery(ctx, "get_job_facets", query)
+	defer func() { end(err) }()
+
+	result := make(JobFacets, len(facets))
+	err = database.Read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query, q.args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		clear(result)
+		for rows.Next() {
+			var facet string
+			var value FacetValue
+			if err := rows.Scan(&facet, &value.Value, &value.Count); err != nil {
+				return err
+			}
+			result[facet] = append(result[facet], value)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, facet := range facets {
+		result[facet] = orderFacet(facet, result[facet])
+	}
+	return result, nil
+}
+
+// orderFacet orders the values of a facet. Salary buckets and employment
+// types keep their own order and are all listed, even without jobs; other
+// values are listed by descending count.
+func orderFacet(facet string, values []FacetValue) []FacetValue {
+	counts := make(map[string]int, len(values))
+	for _, value := range values {
+		counts[value.Value] = value.Count
+	}
+
+	switch facet {
+	case FacetSalary:
+		ordered := make([]FacetValue, len(SalaryBuckets))
+		for i, bucket := range SalaryBuckets {
+			ordered[i] = FacetValue{Value: bucket.Key(), Count: counts[bucket.Key()], Min: bucket.Min, Max: bucket.Max}
+		}
+		return ordered
+	case FacetEmploymentType:
+		ordered := make([]FacetValue, len(EmploymentTypes))
+		for i, employmentType := range EmploymentTypes {
+			ordered[i] = FacetValue{Value: employmentType, Count: counts[employmentType]}
+		}
+		return ordered
+	}
+
+	slices.SortFunc(values, func(a, b FacetValue) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	if values == nil {
+		values = []FacetValue{}
+	}
+	return values
+}
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sort keys of a job listing
+const (
+	SortCreatedAt    = "created_at"
+	SortSalaryMin    = "salary_min"
+	SortSalaryMax    = "salary_max"
+	SortCompany      = "company"
+	SortApplications = "applications"
+
+	// SortRelevance ranks jobs against the search query, so it requires one
+	SortRelevance = "relevance"
+)
+
+// maxSortFields bounds the keys of a sort
+const maxSortFields = 3
+
+var (
+	// ErrInvalidSort is returned when parsing a malformed sort
+	ErrInvalidSort = errors.New("invalid sort")
+
+	// ErrInvalidCursor is returned when decoding a malformed cursor
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// SortField is a key of a job sort and its direction
+type SortField struct {
+	Key  string
+	Desc bool
+}
+
+// JobSort orders a job listing by its fields in turn. Jobs equal on every
+// field are ordered by ID, in the direction of the last field, so that the
+// order is total and cursors are stable.
+type JobSort []SortField
+
+// DefaultJobSort lists the newest jobs first
+var DefaultJobSort = JobSort{{Key: SortCreatedAt, Desc: true}}
+
+// jobSortKey describes how a job listing is sorted by a key
+type jobSortKey struct {
+	// column returns the SQL expression sorted on
+	column func(q *jobQuery) string
+
+	// value returns the value of the key for job, stored in cursors
+	value func(job Job) interface{}
+
+	// decode converts a value decoded from a cursor back to its type
+	decode func(value interface{}) (interface{}, bool)
+}
+
+// jobSortKeys are the keys a job listing can be sorted by
+var jobSortKeys = map[string]jobSortKey{
+	SortCreatedAt: {
+		column: staticColumn("created_at"),
+		value:  func(job Job) interface{} { return job.CreatedAt },
+		decode: decodeTime,
+	},
+	SortSalaryMin: {
+		column: staticColumn("salary_min"),
+		value:  func(job Job) interface{} { return job.SalaryMin },
+		decode: decodeInt,
+	},
+	SortSalaryMax: {
+		column: staticColumn("salary_max"),
+		value:  func(job Job) interface{} { return job.SalaryMax },
+		decode: decodeInt,
+	},
+	SortCompany: {
+		column: staticColumn("company"),
+		value:  func(job Job) interface{} { return job.Company },
+		decode: decodeString,
+	},
+	SortApplications: {
+		column: staticColumn("applications_count"),
+		value:  func(job Job) interface{} { return job.ApplicationsCount },
+		decode: decodeInt,
+	},
+	SortRelevance: {
+		column: (*jobQuery).relevance,
+		value:  func(job Job) interface{} { return job.Relevance },
+		decode: decodeFloat,
+	},
+}
+
+// JobSortKeys returns the keys a job listing can be sorted by
+func JobSortKeys() []string {
+	return []string{SortCreatedAt, SortSalaryMin, SortSalaryMax, SortCompany, SortApplications, SortRelevance}
+}
+
+// ParseJobSort parses a comma-separated list of sort keys, each optionally
+// followed by ":asc" or ":desc". Keys are sorted ascending by default.
+func ParseJobSort(value string) (JobSort, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) > maxSortFields {
+		return nil, ErrInvalidSort
+	}
+
+	sort := make(JobSort, 0, len(parts))
+	for _, part := range parts {
+		key, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if _, ok := jobSortKeys[key]; !ok || sort.Uses(key) {
+			return nil, ErrInvalidSort
+		}
+
+		field := SortField{Key: key}
+		switch direction {
+		case "", "asc":
+		case "desc":
+			field.Desc = true
+		default:
+			return nil, ErrInvalidSort
+		}
+		sort = append(sort, field)
+	}
+
+	return sort, nil
+}
+
+// String returns the canonical form of the sort, which ParseJobSort accepts
+func (s JobSort) String() string {
+	fields := make([]string, len(s))
+	for i, field := range s {
+		fields[i] = field.Key + ":asc"
+		if field.Desc {
+			fields[i] = field.Key + ":desc"
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// MarshalText encodes the sort in its canonical form
+func (s JobSort) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a sort encoded by MarshalText
+func (s *JobSort) UnmarshalText(text []byte) error {
+	sort, err := ParseJobSort(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sort
+	return nil
+}
+
+// Uses reports whether the sort has key
+func (s JobSort) Uses(key string) bool {
+	return slices.ContainsFunc(s, func(field SortField) bool { return field.Key == key })
+}
+
+// Equal reports whether both sorts order jobs the same way
+func (s JobSort) Equal(other JobSort) bool {
+	return slices.Equal(s, other)
+}
+
+// orderBy returns the ORDER BY clause of the sort, with every direction
+// turned around when reverse is set
+func (s JobSort) orderBy(q *jobQuery, reverse bool) string {
+	terms := make([]string, 0, len(s)+1)
+	for _, field := range s {
+		terms = append(terms, jobSortKeys[field.Key].column(q)+direction(field.Desc != reverse))
+	}
+	terms = append(terms, "id"+direction(s.idDesc() != reverse))
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// idDesc reports whether the ID tie-breaker is sorted descending
+func (s JobSort) idDesc() bool {
+	return s[len(s)-1].Desc
+}
+
+// keyset returns the condition selecting the jobs past cursor in the order
+// of the sort, or before it for a Before cursor
+func (s JobSort) keyset(q *jobQuery, cursor JobCursor) string {
+	columns := make([]string, 0, len(s)+1)
+	descs := make([]bool, 0, len(s)+1)
+	for _, field := range s {
+		columns = append(columns, jobSortKeys[field.Key].column(q))
+		descs = append(descs, field.Desc)
+	}
+	columns = append(columns, "id")
+	descs = append(descs, s.idDesc())
+
+	params := make([]string, len(cursor.Values))
+	for i, value := range cursor.Values {
+		params[i] = q.arg(value)
+	}
+
+	operators := make([]string, len(descs))
+	for i, desc := range descs {
+		operators[i] = ">"
+		if desc != cursor.Before {
+			operators[i] = "<"
+		}
+	}
+
+	// A row comparison matches a multicolumn index when every key goes the same way
+	if !slices.ContainsFunc(operators, func(op string) bool { return op != operators[0] }) {
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operators[0], strings.Join(params, ", "))
+	}
+
+	// Otherwise each key breaks the ties of the previous ones
+	last := len(columns) - 1
+	condition := fmt.Sprintf("%s %s %s", columns[last], operators[last], params[last])
+	for i := last - 1; i >= 0; i-- {
+		condition = fmt.Sprintf("(%s %s %s OR (%s = %s AND %s))",
+			columns[i], operators[i], params[i], columns[i], params[i], condition)
+	}
+	return condition
+}
+
+// CursorAfter returns the cursor reading the jobs listed after job
+func (s JobSort) CursorAfter(job Job) JobCursor {
+	values := make([]interface{}, 0, len(s)+1)
+	for _, field := range s {
+		values = append(values, jobSortKeys[field.Key].value(job))
+	}
+	return JobCursor{Sort: s, Values: append(values, job.ID)}
+}
+
+// CursorBefore returns the cursor reading the jobs listed before job
+func (s JobSort) CursorBefore(job Job) JobCursor {
+	cursor := s.CursorAfter(job)
+	cursor.Before = true
+	return cursor
+}
+
+// JobCursor is a position in a sorted job listing: the values of the sort
+// keys and the ID of a job. Listings read from a cursor continue after it,
+// or before it when Before is set, and stay stable while jobs are posted.
+type JobCursor struct {
+	Sort   JobSort       `json:"s"`
+	Values []interface{} `json:"v"`
+	Before bool          `json:"b,omitempty"`
+}
+
+// Encode returns the opaque form of the cursor sent to clients
+func (c JobCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeJobCursor parses a cursor returned by Encode
+func DecodeJobCursor(value string) (JobCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return JobCursor{}, ErrInvalidCursor
+	}
+
+	var cursor JobCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Sort) == 0 || len(cursor.Values) != len(cursor.Sort)+1 {
+		return JobCursor{}, ErrInvalidCursor
+	}
+
+	// Restore the types lost in JSON, which the query arguments need
+	for i, field := range cursor.Sort {
+		decoded, ok := jobSortKeys[field.Key].decode(cursor.Values[i])
+		if !ok {
+			return JobCursor{}, ErrInvalidCursor
+		}
+		cursor.Values[i] = decoded
+	}
+
+	id, ok := decodeInt(cursor.Values[len(cursor.Sort)])
+	if !ok || id.(int64) < 1 {
+		return JobCursor{}, ErrInvalidCursor
+	}
+	cursor.Values[len(cursor.Sort)] = id
+
+	return cursor, nil
+}
+
+// direction returns the SQL sort direction
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// staticColumn returns a sort column that does not depend on the query
+func staticColumn(name string) func(q *jobQuery) string {
+	return func(*jobQuery) string { return name }
+}
+
+func decodeTime(value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+func decodeInt(value interface{}) (interface{}, bool) {
+	f, ok := value.(float64)
+	if !ok || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		return nil, false
+	}
+	return int64(f), true
+}
+
+func decodeString(value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	return s, ok
+}
+
+func decodeFloat(value interface{}) (interface{}, bool) {
+	f, ok := value.(float64)
+	return f, ok
+}
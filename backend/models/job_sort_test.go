@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rawCursor encodes a cursor from its JSON form, as a client could forge it
+func rawCursor(json string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(json))
+}
+
+func TestDecodeJobCursorRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"bad base64", "not base64!"},
+		{"padded base64", rawCursor(`{"s":"company:asc","v":["x",1]}`) + "=="},
+		{"not json", rawCursor(`created_at:desc`)},
+		{"no sort", rawCursor(`{"v":[1]}`)},
+		{"empty sort", rawCursor(`{"s":"","v":[1]}`)},
+		{"unknown sort key", rawCursor(`{"s":"password:desc","v":["x",1]}`)},
+		{"unknown sort direction", rawCursor(`{"s":"company:up","v":["x",1]}`)},
+		{"repeated sort key", rawCursor(`{"s":"company,company","v":["x","y",1]}`)},
+		{"too many sort keys", rawCursor(`{"s":"company,salary_min,salary_max,applications","v":["x",1,2,3,1]}`)},
+		{"no values", rawCursor(`{"s":"company:asc"}`)},
+		{"missing id", rawCursor(`{"s":"company:asc","v":["x"]}`)},
+		{"extra value", rawCursor(`{"s":"company:asc","v":["x",1,2]}`)},
+		{"string id", rawCursor(`{"s":"company:asc","v":["x","1"]}`)},
+		{"fractional id", rawCursor(`{"s":"company:asc","v":["x",1.5]}`)},
+		{"zero id", rawCursor(`{"s":"company:asc","v":["x",0]}`)},
+		{"negative id", rawCursor(`{"s":"company:asc","v":["x",-1]}`)},
+		{"huge id", rawCursor(`{"s":"company:asc","v":["x",1e20]}`)},
+		{"bad time", rawCursor(`{"s":"created_at:desc","v":["yesterday",1]}`)},
+		{"numeric time", rawCursor(`{"s":"created_at:desc","v":[1736937000,1]}`)},
+		{"fractional salary", rawCursor(`{"s":"salary_min:asc","v":[1.5,1]}`)},
+		{"string salary", rawCursor(`{"s":"salary_max:asc","v":["3000000",1]}`)},
+		{"numeric company", rawCursor(`{"s":"company:asc","v":[42,1]}`)},
+		{"string relevance", rawCursor(`{"s":"relevance:desc","v":["0.5",1]}`)},
+		{"null value", rawCursor(`{"s":"applications:desc","v":[null,1]}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeJobCursor(tt.cursor)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeJobCursor() = %+v, %v, want ErrInvalidCursor", cursor, err)
+			}
+		})
+	}
+}
+
+func TestJobCursorRoundTrip(t *testing.T) {
+	job := Job{
+		ID:                42,
+		Company:           "TechCorp Indonesia",
+		SalaryMin:         3000000,
+		SalaryMax:         8000000,
+		CreatedAt:         time.Date(2025, 1, 15, 10, 30, 0, 123456789, time.UTC),
+		ApplicationsCount: 12,
+		Relevance:         0.0608,
+	}
+
+	// Values come back with the types the query arguments need
+	want := map[string]interface{}{
+		SortCreatedAt:    job.CreatedAt,
+		SortSalaryMin:    int64(job.SalaryMin),
+		SortSalaryMax:    int64(job.SalaryMax),
+		SortCompany:      job.Company,
+		SortApplications: int64(job.ApplicationsCount),
+		SortRelevance:    job.Relevance,
+	}
+
+	for _, key := range JobSortKeys() {
+		for _, before := range []bool{false, true} {
+			sort := JobSort{{Key: key, Desc: true}}
+			cursor := sort.CursorAfter(job)
+			if before {
+				cursor = sort.CursorBefore(job)
+			}
+
+			decoded, err := DecodeJobCursor(cursor.Encode())
+			if err != nil {
+				t.Fatalf("%s: DecodeJobCursor() error = %v", key, err)
+			}
+			if !decoded.Sort.Equal(sort) || decoded.Before != before || len(decoded.Values) != 2 {
+				t.Fatalf("%s: decoded %+v, want sort %v before %v", key, decoded, sort, before)
+			}
+
+			if got, ok := decoded.Values[0].(time.Time); ok {
+				if !got.Equal(job.CreatedAt) {
+					t.Errorf("%s: value %v, want %v", key, got, job.CreatedAt)
+				}
+			} else if !reflect.DeepEqual(decoded.Values[0], want[key]) {
+				t.Errorf("%s: value %#v, want %#v", key, decoded.Values[0], want[key])
+			}
+			if decoded.Values[1] != int64(job.ID) {
+				t.Errorf("%s: id %#v, want %d", key, decoded.Values[1], job.ID)
+			}
+		}
+	}
+
+	// Every key of a multi-key sort keeps its value
+	sort := JobSort{{Key: SortSalaryMax, Desc: true}, {Key: SortCompany}, {Key: SortCreatedAt, Desc: true}}
+	decoded, err := DecodeJobCursor(sort.CursorAfter(job).Encode())
+	if err != nil {
+		t.Fatalf("DecodeJobCursor() error = %v", err)
+	}
+	if !decoded.Sort.Equal(sort) || decoded.Values[0] != int64(job.SalaryMax) || decoded.Values[1] != job.Company ||
+		!decoded.Values[2].(time.Time).Equal(job.CreatedAt) || decoded.Values[3] != int64(job.ID) {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
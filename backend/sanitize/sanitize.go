@@ -0,0 +1,326 @@
+package sanitize
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Policy describes which markup survives sanitization
+type Policy struct {
+	// elements maps each allowed element to its allowed attributes
+	elements map[string]map[string]bool
+
+	// urlAttributes are attributes whose values must be safe URLs
+	urlAttributes map[string]bool
+
+	// allowedSchemes are the URL schemes accepted in URL attributes and markdown links
+	allowedSchemes map[string]bool
+
+	// plainText strips every element and returns unescaped text
+	plainText bool
+}
+
+// dropContent lists elements whose content is removed along with the element
+var dropContent = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+	"noembed":  true,
+	"noframes": true,
+	"svg":      true,
+	"math":     true,
+}
+
+// voidElements are written without a closing tag
+var voidElements = map[string]bool{
+	"br": true,
+	"hr": true,
+}
+
+// Markdown link targets, in sanitized text where "<" is escaped. An inline
+// link or image target follows "](", a reference definition starts a line
+// with "[label]:", and either may be wrapped in angle brackets.
+var (
+	markdownLink       = regexp.MustCompile(`(\]\(\s*)(?:&lt;([^>\n]*)>|([^)\s]*))`)
+	markdownDefinition = regexp.MustCompile(`(?m)(^ {0,3}\[[^\]\n]+\]:[ \t]*)(?:&lt;([^>\n]*)>|(\S*))`)
+)
+
+// markdownAngleTarget matches a link target in angle brackets in raw input,
+// which the HTML tokenizer would otherwise read as a tag and drop
+var markdownAngleTarget = regexp.MustCompile(`(?m)(\]\(\s*|^ {0,3}\[[^\]\n]+\]:[ \t]*)<([^<>\n]*)>`)
+
+// PlainText strips all markup, for names and other single line fields
+var PlainText = &Policy{plainText: true}
+
+// UserHTML allows basic formatting markup and markdown, for job descriptions
+var UserHTML = &Policy{
+	elements: map[string]map[string]bool{
+		"p":          {},
+		"br":         {},
+		"hr":         {},
+		"strong":     {},
+		"b":          {},
+		"em":         {},
+		"i":          {},
+		"u":          {},
+		"s":          {},
+		"ul":         {},
+		"ol":         {},
+		"li":         {},
+		"blockquote": {},
+		"code":       {},
+		"pre":        {},
+		"h3":         {},
+		"h4":         {},
+		"a":          {"href": true, "title": true},
+	},
+	urlAttributes: map[string]bool{"href": true},
+	allowedSchemes: map[string]bool{
+		"http":   true,
+		"https":  true,
+		"mailto": true,
+	},
+}
+
+// policies maps `sanitize` struct tag values to policies
+var policies = map[string]*Policy{
+	"text": PlainText,
+	"html": UserHTML,
+}
+
+// Sanitize applies the policy to input
+func (p *Policy) Sanitize(input string) string {
+	if !p.plainText {
+		return p.sanitize(input)
+	}
+
+	// Plain text is returned unescaped, so decoded entities such as
+	// "&lt;script&gt;" are sanitized again until a pass changes nothing and
+	// no markup is left. Every pass that changes the text shortens it, so
+	// the loop ends however deeply the input is encoded.
+	output := p.sanitize(input)
+	for {
+		next := p.sanitize(output)
+		if next == output {
+			return output
+		}
+		output = next
+	}
+}
+
+// sanitize makes a single tokenizer pass over input
+func (p *Policy) sanitize(input string) string {
+	if !p.plainText {
+		input = markdownAngleTarget.ReplaceAllString(input, "$1&lt;$2>")
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+
+	var b strings.Builder
+	var open []string
+	skipDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			if p.plainText {
+				b.WriteString(token.Data)
+			} else {
+				b.WriteString(escapeText(token.Data))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if dropContent[token.Data] {
+				if tokenType == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			attributes, ok := p.elements[token.Data]
+			if !ok {
+				continue
+			}
+
+			b.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				if attr.Namespace != "" || !attributes[attr.Key] {
+					continue
+				}
+				if p.urlAttributes[attr.Key] && !p.safeURL(attr.Val) {
+					continue
+				}
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			if token.Data == "a" {
+				b.WriteString(` rel="nofollow noopener noreferrer"`)
+			}
+			b.WriteString(">")
+
+			if !voidElements[token.Data] && tokenType == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			if dropContent[token.Data] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			// Close the element and anything left open inside it
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+
+		// Comments and doctypes are always dropped
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+
+	output := stripControl(b.String())
+	if !p.plainText {
+		// Links are checked on the whole output, since dropped tags or control
+		// characters may have split a target across text tokens
+		output = p.neutralizeMarkdownLinks(output)
+	}
+	return strings.TrimSpace(output)
+}
+
+// safeURL reports whether value is a relative URL or uses an allowed scheme
+func (p *Policy) safeURL(value string) bool {
+	// Browsers ignore whitespace and control characters inside schemes ("java\tscript:")
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, value)
+
+	if strings.HasPrefix(cleaned, "//") || strings.HasPrefix(cleaned, `\\`) {
+		return false
+	}
+
+	parsed, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+
+	if parsed.Scheme == "" {
+		// A colon before any slash would be read as a scheme by browsers
+		return !strings.Contains(strings.SplitN(cleaned, "/", 2)[0], ":")
+	}
+
+	return p.allowedSchemes[strings.ToLower(parsed.Scheme)]
+}
+
+// neutralizeMarkdownLinks replaces the targets of markdown links, images and
+// reference definitions that use a disallowed scheme with "#"
+func (p *Policy) neutralizeMarkdownLinks(text string) string {
+	for _, re := range []*regexp.Regexp{markdownLink, markdownDefinition} {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			groups := re.FindStringSubmatch(match)
+			if p.safeURL(unescapeAll(groups[2] + groups[3])) {
+				return match
+			}
+			return groups[1] + "#"
+		})
+	}
+	return text
+}
+
+// unescapeAll decodes entities until none are left, as markdown renderers
+// decode them in link targets ("javascript&colon;")
+func unescapeAll(text string) string {
+	for {
+		next := html.UnescapeString(text)
+		if next == text {
+			return text
+		}
+		text = next
+	}
+}
+
+// escapeText escapes the characters that could start markup, leaving markdown
+// syntax such as ">" quotes untouched
+func escapeText(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	return strings.ReplaceAll(text, "<", "&lt;")
+}
+
+// stripControl removes control characters other than newlines and tabs
+func stripControl(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || r == '\r' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// Struct sanitizes the string fields of a struct pointer according to their
+// `sanitize` tag ("text" or "html"). Untagged fields are left unchanged.
+func Struct(ptr interface{}) {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	value = value.Elem()
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		policy, ok := policies[structType.Field(i).Tag.Get("sanitize")]
+		if !ok {
+			continue
+		}
+
+		field := value.Field(i)
+		switch {
+		case field.Kind() == reflect.String && field.CanSet():
+			field.SetString(policy.Sanitize(field.String()))
+		case field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.String:
+			field.Elem().SetString(policy.Sanitize(field.Elem().String()))
+		}
+	}
+}
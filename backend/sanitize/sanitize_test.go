@@ -0,0 +1,177 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// xssPayloads is a corpus of markup injection attempts. None of them may
+// leave executable markup in the output of any policy.
+var xssPayloads = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=//evil.example/x.js></SCRIPT>`,
+	`<img src=x onerror=alert(1)>`,
+	`<svg onload=alert(1)>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+	`<iframe src="javascript:alert(1)"></iframe>`,
+	`<body onload=alert(1)>`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<a href="JaVaScRiPt:alert(1)">x</a>`,
+	`<a href="java&#x09;script:alert(1)">x</a>`,
+	`<a href=" javascript:alert(1)">x</a>`,
+	`<a href="vbscript:msgbox(1)">x</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+	`<a href="//evil.example">x</a>`,
+	`<a href="\\evil.example">x</a>`,
+	`<p onclick="alert(1)" style="x:expression(alert(1))">x</p>`,
+	`<scr<script>ipt>alert(1)</script>`,
+	`<<script>script>alert(1)<</script>/script>`,
+	`<!--<script>alert(1)</script>-->`,
+	`<style>@import 'javascript:alert(1)';</style>`,
+	`<textarea><script>alert(1)</script></textarea>`,
+	`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+	`&lt;script&gt;alert(1)&lt;/script&gt;`,
+	`&amp;lt;script&amp;gt;alert(1)&amp;lt;/script&amp;gt;`,
+	`&amp;amp;amp;amp;lt;script&amp;amp;amp;amp;gt;alert(1)&amp;amp;amp;amp;lt;/script&amp;amp;amp;amp;gt;`,
+	`&#60;script&#62;alert(1)&#60;/script&#62;`,
+	`&#x3C;img src=x onerror=alert(1)&#x3E;`,
+	`[x](javascript:alert(1))`,
+	`[x](  JavaScript:alert(1))`,
+	`[x](<javascript:alert(1)>)`,
+	`[x](javascript&colon;alert(1))`,
+	`[x](java<b>script:alert(1))`,
+	`[x](java<foo>script:alert(1))`,
+	`![x](javascript:alert(1))`,
+	`[x]: javascript:alert(1)`,
+	`  [x]: <javascript:alert(1)>`,
+	"[x]: java\x00script:alert(1)",
+}
+
+// unsafeSchemes must not survive in link targets, even entity-encoded
+var unsafeSchemes = []string{"javascript:", "vbscript:", "data:"}
+
+func TestSanitizeXSSCorpus(t *testing.T) {
+	for name, policy := range policies {
+		for _, payload := range xssPayloads {
+			output := policy.Sanitize(payload)
+			if problem := unsafeOutput(policy, output); problem != "" {
+				t.Errorf("%s policy: %q sanitized to %q: %s", name, payload, output, problem)
+			}
+		}
+	}
+}
+
+// unsafeOutput describes what makes output unsafe for policy, or returns ""
+func unsafeOutput(policy *Policy, output string) string {
+	// Plain text is displayed as text, so it must not contain any markup
+	if policy.plainText {
+		tokenizer := html.NewTokenizer(strings.NewReader(output))
+		for tokenType := tokenizer.Next(); tokenType != html.ErrorToken; tokenType = tokenizer.Next() {
+			if tokenType != html.TextToken {
+				return "markup " + tokenizer.Token().String()
+			}
+		}
+		return ""
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(output))
+	for tokenType := tokenizer.Next(); tokenType != html.ErrorToken; tokenType = tokenizer.Next() {
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			attributes, ok := policy.elements[token.Data]
+			if !ok {
+				return "element " + token.Data
+			}
+			for _, attr := range token.Attr {
+				if !attributes[attr.Key] && attr.Key != "rel" {
+					return "attribute " + attr.Key
+				}
+				if policy.urlAttributes[attr.Key] && !policy.safeURL(attr.Val) {
+					return "URL " + attr.Val
+				}
+			}
+		case html.CommentToken, html.DoctypeToken:
+			return "comment or doctype"
+		}
+	}
+
+	// Markdown renderers decode entities in link targets
+	decoded := strings.ToLower(unescapeAll(output))
+	for _, scheme := range unsafeSchemes {
+		if strings.Contains(decoded, scheme) {
+			return "scheme " + scheme
+		}
+	}
+	return ""
+}
+
+func TestUserHTML(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"allowed markup", `<p>Build <strong>fast</strong> APIs</p>`, `<p>Build <strong>fast</strong> APIs</p>`},
+		{"unclosed element", `<ul><li>Go`, `<ul><li>Go</li></ul>`},
+		{"unknown element", `<div>text</div>`, `text`},
+		{"safe link", `<a href="https://example.com" onclick="x">x</a>`, `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{"unsafe link", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"text is escaped", `1 < 2 & 3`, `1 &lt; 2 &amp; 3`},
+		{"markdown quote", `> quoted`, `> quoted`},
+		{"safe markdown link", `[docs](https://example.com/docs)`, `[docs](https://example.com/docs)`},
+		{"safe angle link", `[docs](<https://example.com/a b>)`, `[docs](&lt;https://example.com/a b>)`},
+		{"unsafe markdown link", `[x](javascript:alert(1))`, `[x](#))`},
+		{"unsafe angle link", `[x](<javascript:alert(1)>)`, `[x](#)`},
+		{"unsafe encoded link", `[x](javascript&colon;alert(1))`, `[x](#))`},
+		{"split link", `[x](java<foo>script:alert(1))`, `[x](#))`},
+		{"safe definition", `[x]: https://example.com`, `[x]: https://example.com`},
+		{"unsafe definition", "intro\n[x]: javascript:alert(1)", "intro\n[x]: #"},
+		{"unsafe angle definition", `[x]: <javascript:alert(1)>`, `[x]: #`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UserHTML.Sanitize(tt.input); got != tt.want {
+				t.Errorf("UserHTML.Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"plain", `Jakarta`, `Jakarta`},
+		{"markup", `<b>Tech</b>Corp`, `TechCorp`},
+		{"entities", `R&amp;D`, `R&D`},
+		{"encoded script", `&lt;script&gt;alert(1)&lt;/script&gt;`, ``},
+		{"deeply encoded script", `&amp;amp;amp;amp;lt;script&amp;amp;amp;amp;gt;alert(1)&amp;amp;amp;amp;lt;/script&amp;amp;amp;amp;gt;`, ``},
+		{"control characters", "Tech\x00Corp\x1b", `TechCorp`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText.Sanitize(tt.input); got != tt.want {
+				t.Errorf("PlainText.Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStruct(t *testing.T) {
+	name := "<i>Ana</i>"
+	input := struct {
+		Name        *string `sanitize:"text"`
+		Description string  `sanitize:"html"`
+		Raw         string
+	}{&name, `<p onclick="x">Hi</p>`, `<b>kept</b>`}
+
+	Struct(&input)
+
+	if *input.Name != "Ana" || input.Description != "<p>Hi</p>" || input.Raw != "<b>kept</b>" {
+		t.Errorf("Struct sanitized to %q, %q, %q", *input.Name, input.Description, input.Raw)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"job-portal-backend/cache"
+	"job-portal-backend/config"
+	"job-portal-backend/database"
+	"job-portal-backend/health"
+	"job-portal-backend/logger"
+	"job-portal-backend/mailer"
+	"job-portal-backend/middleware"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runServer serves handler on addr until SIGINT or SIGTERM, then shuts down
+// gracefully: readiness turns not_ready, in-flight requests drain, background
+// work stops and the database and Redis connections are closed, in that order
+func runServer(handler http.Handler, cfg config.ServerConfig) {
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "port", cfg.Port)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		logger.Fatal("Failed to start server", "error", err)
+	case <-ctx.Done():
+	}
+
+	// A second signal terminates immediately
+	stop()
+
+	slog.Info("Shutdown started, draining requests", "delay", cfg.ShutdownDelay.String(), "timeout", cfg.ShutdownTimeout.String())
+	health.SetDraining()
+	time.Sleep(cfg.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server did not drain in time", "error", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server stopped with error", "error", err)
+	}
+
+	// Stop background work before closing the connections it may use
+	middleware.StopRateLimiter()
+	if err := mailer.Wait(shutdownCtx); err != nil {
+		slog.Error("Pending emails were not sent", "error", err)
+	}
+
+	if err := database.Close(); err != nil {
+		slog.Error("Error closing database", "error", err)
+	}
+	if err := cache.Close(); err != nil {
+		slog.Error("Error closing Redis", "error", err)
+	}
+
+	slog.Info("Server stopped")
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters selected by OTEL_TRACES_EXPORTER
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterNone   = "none"
+)
+
+// defaultServiceName is used when OTEL_SERVICE_NAME is not set
+const defaultServiceName = "job-portal-backend"
+
+// instrumentationName names the tracer used by this application
+const instrumentationName = "job-portal-backend"
+
+// Init installs the global tracer provider and W3C trace context propagator.
+//
+// OTEL_TRACES_EXPORTER selects the exporter: otlp (OTLP over HTTP, configured
+// by the standard OTEL_EXPORTER_OTLP_* variables), stdout, file (written to
+// OTEL_TRACES_FILE) or none. It defaults to otlp when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set and none otherwise. Sampling follows
+// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG.
+//
+// The returned function flushes pending spans and must be called on shutdown.
+func Init() func(context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER")))
+	if exporterName == "" {
+		exporterName = ExporterNone
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			exporterName = ExporterOTLP
+		}
+	}
+
+	if exporterName == ExporterNone {
+		slog.Info("Tracing disabled")
+		return func(context.Context) error { return nil }
+	}
+
+	exporter, closeExporter, err := newExporter(exporterName)
+	if err != nil {
+		slog.Warn("Tracing exporter unavailable, continuing without tracing", "exporter", exporterName, "error", err)
+		return func(context.Context) error { return nil }
+	}
+
+	res, err := newResource()
+	if err != nil {
+		// Partial resources are still usable, e.g. when a detector fails
+		slog.Warn("Tracing resource incomplete", "error", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	slog.Info("Tracing initialized", "exporter", exporterName)
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeExporter())
+	}
+}
+
+// newExporter creates the span exporter called name and a function closing
+// any file it writes to
+func newExporter(name string) (sdktrace.SpanExporter, func() error, error) {
+	noop := func() error { return nil }
+
+	switch name {
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(context.Background())
+		return exporter, noop, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, noop, err
+	case ExporterFile:
+		path := os.Getenv("OTEL_TRACES_FILE")
+		if path == "" {
+			path = "traces.jsonl"
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	default:
+		return nil, nil, errors.New("unknown exporter " + name)
+	}
+}
+
+// newResource describes this service, honouring OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES
+func newResource() (*resource.Resource, error) {
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	return resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(serviceName), semconv.ServiceVersion("1.0")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+}
+
+// Tracer returns the application tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -8,6 +8,8 @@ export interface Job {
 	salary_min: number;
 	salary_max: number;
 	created_at: string;
+	// Only set when searching
+	relevance?: number;
 }
 
 export interface Application {
@@ -16,7 +18,9 @@ export interface Application {
 	name: string;
 	email: string;
 	cv_filename: string;
+	status?: 'submitted' | 'withdrawn';
 	applied_at: string;
+	updated_at?: string;
 	job?: Job;
 }
 
@@ -25,6 +29,8 @@ export interface JobFilter {
 	salary_min?: number;
 	salary_max?: number;
 	search?: string;
+	// Comma-separated sort keys, e.g. 'salary_min:desc'; searches sort by relevance by default
+	sort?: string;
 }
 
 export interface PaginationInfo {
@@ -36,15 +42,27 @@ export interface PaginationInfo {
 	has_prev: boolean;
 }
 
+export interface FacetValue {
+	value: string;
+	count: number;
+	min?: number;
+	max?: number;
+}
+
+export type JobFacets = Partial<Record<'location' | 'salary' | 'employment_type' | 'company', FacetValue[]>>;
+
 export interface PaginatedResponse {
 	jobs: Job[];
 	pagination: PaginationInfo;
+	facets?: JobFacets;
 }
 
 class ApiClient {
 	private async request<T>(endpoint: string, options: RequestInit = {}): Promise<T> {
 		const url = `${API_BASE_URL}${endpoint}`;
 		const response = await fetch(url, {
+			// Send the API's read_primary_until cookie so reads right after a write see it
+			credentials: 'include',
 			...options,
 			headers: {
 				'Content-Type': 'application/json',
@@ -54,20 +72,28 @@ class ApiClient {
 
 		if (!response.ok) {
 			const error = await response.json().catch(() => ({ error: 'Network error' }));
-			throw new Error(error.error || `HTTP ${response.status}`);
+			throw new Error(error.message || error.error || `HTTP ${response.status}`);
 		}
 
 		return response.json();
 	}
 
-	async getJobs(filters: JobFilter = {}, page: number = 1, limit: number = 12): Promise<PaginatedResponse> {
+	// Candidate endpoints are authenticated by the magic link token
+	private candidateHeaders(token: string): Record<string, string> {
+		return { Authorization: `Bearer ${token}` };
+	}
+
+	async getJobs(filters: JobFilter = {}, page: number = 1, limit: number = 12, facets: string[] = []): Promise<PaginatedResponse> {
 		const params = new URLSearchParams();
 		if (filters.location) params.append('location', filters.location);
 		if (filters.salary_min) params.append('salary_min', filters.salary_min.toString());
 		if (filters.salary_max) params.append('salary_max', filters.salary_max.toString());
-		if (filters.search) params.append('search', filters.search);
+		if (filters.search) params.append('q', filters.search);
+		const sort = filters.sort || (filters.search ? 'relevance:desc' : '');
+		if (sort) params.append('sort', sort);
 		params.append('page', page.toString());
 		params.append('limit', limit.toString());
+		if (facets.length > 0) params.append('facets', facets.join(','));
 
 		const url = `/jobs?${params.toString()}`;
 		console.log('=== API REQUEST DEBUG ===');
@@ -93,6 +119,7 @@ class ApiClient {
 		const response = await fetch(url, {
 			method: 'POST',
 			body: formData,
+			credentials: 'include',
 			// Don't set Content-Type header for FormData, let browser set it automatically
 		});
 
@@ -111,8 +138,36 @@ class ApiClient {
 		return response.json();
 	}
 
-	async getApplications(): Promise<Application[]> {
-		return this.request<Application[]>('/applications');
+	async getMyApplications(token: string): Promise<Application[]> {
+		return this.request<Application[]>('/candidate/applications', {
+			headers: this.candidateHeaders(token),
+		});
+	}
+
+	async withdrawApplication(id: number, token: string): Promise<Application> {
+		return this.request<Application>(`/candidate/applications/${id}/withdraw`, {
+			method: 'POST',
+			headers: this.candidateHeaders(token),
+		});
+	}
+
+	async replaceApplicationCV(id: number, cv: File, token: string): Promise<Application> {
+		const formData = new FormData();
+		formData.append('cv', cv);
+
+		const response = await fetch(`${API_BASE_URL}/candidate/applications/${id}/cv`, {
+			method: 'PUT',
+			body: formData,
+			credentials: 'include',
+			headers: this.candidateHeaders(token),
+		});
+
+		if (!response.ok) {
+			const error = await response.json().catch(() => ({ error: 'Network error' }));
+			throw new Error(error.message || error.error || `HTTP ${response.status}`);
+		}
+
+		return response.json();
 	}
 }
 